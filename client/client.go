@@ -0,0 +1,320 @@
+// Package client es un cliente tipado para /api/v2. Lo usan cmd/cliente y
+// cualquier otra herramienta que necesite consultar el server.
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Client struct {
+	BaseURL string // por ejemplo http://10.10.28.60:8080/api/v2
+	HTTP    *http.Client
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, HTTP: http.DefaultClient}
+}
+
+// ListOptions son los parametros de paginacion, orden y filtro de los
+// listados. Los campos vacios no se envian.
+type ListOptions struct {
+	Limit   int
+	Offset  int
+	Sort    string
+	Team    string
+	Country string
+	Year    int
+	Circuit string
+	// Type elige en Races "race" (por defecto), "sprint" o "all".
+	Type string
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+	if o.Team != "" {
+		q.Set("team", o.Team)
+	}
+	if o.Country != "" {
+		q.Set("country", o.Country)
+	}
+	if o.Year != 0 {
+		q.Set("year", strconv.Itoa(o.Year))
+	}
+	if o.Circuit != "" {
+		q.Set("circuit", o.Circuit)
+	}
+	if o.Type != "" {
+		q.Set("type", o.Type)
+	}
+	return q
+}
+
+// APIError es la respuesta de error del server.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("error del servidor: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("error del servidor (%d): %s", e.StatusCode, e.Message)
+}
+
+func (c *Client) ListDrivers(opts ListOptions) (*DriverList, error) {
+	var out DriverList
+	return &out, c.get("/drivers", opts.query(), &out)
+}
+
+// AllDrivers recorre todas las paginas de ListDrivers.
+func (c *Client) AllDrivers(opts ListOptions) ([]Driver, error) {
+	var todos []Driver
+	opts.Limit = 100
+	for {
+		pagina, err := c.ListDrivers(opts)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, pagina.Data...)
+		if pagina.Pagination.NextOffset == nil {
+			return todos, nil
+		}
+		opts.Offset = *pagina.Pagination.NextOffset
+	}
+}
+
+func (c *Client) DriverDetail(driverNumber int) (*DriverDetail, error) {
+	var out DriverDetail
+	return &out, c.get("/drivers/"+strconv.Itoa(driverNumber), nil, &out)
+}
+
+// HeadToHead compara dos pilotos en las carreras que largaron ambos.
+func (c *Client) HeadToHead(a, b int) (*HeadToHead, error) {
+	var out HeadToHead
+	q := url.Values{"a": {strconv.Itoa(a)}, "b": {strconv.Itoa(b)}}
+	return &out, c.get("/drivers/compare", q, &out)
+}
+
+func (c *Client) Races(opts ListOptions) (*RaceList, error) {
+	var out RaceList
+	return &out, c.get("/races", opts.query(), &out)
+}
+
+// AllRaces recorre todas las paginas de Races.
+func (c *Client) AllRaces(opts ListOptions) ([]Race, error) {
+	var todas []Race
+	opts.Limit = 100
+	for {
+		pagina, err := c.Races(opts)
+		if err != nil {
+			return nil, err
+		}
+		todas = append(todas, pagina.Data...)
+		if pagina.Pagination.NextOffset == nil {
+			return todas, nil
+		}
+		opts.Offset = *pagina.Pagination.NextOffset
+	}
+}
+
+func (c *Client) Meetings(opts ListOptions) (*MeetingList, error) {
+	var out MeetingList
+	return &out, c.get("/meetings", opts.query(), &out)
+}
+
+// AllMeetings recorre todas las paginas de Meetings.
+func (c *Client) AllMeetings(opts ListOptions) ([]Meeting, error) {
+	var todas []Meeting
+	opts.Limit = 100
+	for {
+		pagina, err := c.Meetings(opts)
+		if err != nil {
+			return nil, err
+		}
+		todas = append(todas, pagina.Data...)
+		if pagina.Pagination.NextOffset == nil {
+			return todas, nil
+		}
+		opts.Offset = *pagina.Pagination.NextOffset
+	}
+}
+
+// Meeting devuelve un fin de semana con todas sus sesiones.
+func (c *Client) Meeting(meetingKey int) (*MeetingDetail, error) {
+	var out MeetingDetail
+	return &out, c.get("/meetings/"+strconv.Itoa(meetingKey), nil, &out)
+}
+
+// Practice analiza las tandas cortas y largas de una practica libre.
+func (c *Client) Practice(sessionKey int) (*PracticeAnalysis, error) {
+	var out PracticeAnalysis
+	return &out, c.get("/practice/"+strconv.Itoa(sessionKey), nil, &out)
+}
+
+func (c *Client) RaceDetail(sessionKey int) (*RaceDetail, error) {
+	var out RaceDetail
+	return &out, c.get("/races/"+strconv.Itoa(sessionKey), nil, &out)
+}
+
+// RacePace devuelve el ritmo de carrera de cada piloto calculado con sus
+// vueltas limpias.
+func (c *Client) RacePace(sessionKey int) (*RacePace, error) {
+	var out RacePace
+	return &out, c.get(fmt.Sprintf("/races/%d/pace", sessionKey), nil, &out)
+}
+
+// RaceLeaders devuelve el lider de cada vuelta y las vueltas lideradas por
+// cada piloto.
+func (c *Client) RaceLeaders(sessionKey int) (*RaceLeaders, error) {
+	var out RaceLeaders
+	return &out, c.get(fmt.Sprintf("/races/%d/leaders", sessionKey), nil, &out)
+}
+
+// LapTelemetry devuelve la telemetria de una vuelta reducida a points puntos
+// (0 usa el valor por defecto del server).
+func (c *Client) LapTelemetry(sessionKey, driverNumber, lapNumber, points int) (*LapTelemetry, error) {
+	var out LapTelemetry
+	q := url.Values{}
+	if points > 0 {
+		q.Set("points", strconv.Itoa(points))
+	}
+	return &out, c.get(fmt.Sprintf("/races/%d/drivers/%d/laps/%d/telemetry", sessionKey, driverNumber, lapNumber), q, &out)
+}
+
+// CompareLaps alinea por distancia la vuelta lapA de driverA con la vuelta
+// lapB de driverB, con a lo sumo points puntos (0 usa el valor del server).
+func (c *Client) CompareLaps(sessionKey, driverA, lapA, driverB, lapB, points int) (*LapComparison, error) {
+	var out LapComparison
+	q := url.Values{}
+	q.Set("a", fmt.Sprintf("%d:%d", driverA, lapA))
+	q.Set("b", fmt.Sprintf("%d:%d", driverB, lapB))
+	if points > 0 {
+		q.Set("points", strconv.Itoa(points))
+	}
+	return &out, c.get(fmt.Sprintf("/races/%d/laps/compare", sessionKey), q, &out)
+}
+
+// RaceGaps devuelve la diferencia al lider y al auto de adelante de cada
+// piloto vuelta a vuelta. Si drivers no esta vacio se limita a esos pilotos.
+func (c *Client) RaceGaps(sessionKey int, drivers []int) (*RaceGaps, error) {
+	var out RaceGaps
+	q := url.Values{}
+	if len(drivers) > 0 {
+		numeros := make([]string, len(drivers))
+		for i, d := range drivers {
+			numeros[i] = strconv.Itoa(d)
+		}
+		q.Set("drivers", strings.Join(numeros, ","))
+	}
+	return &out, c.get(fmt.Sprintf("/races/%d/gaps", sessionKey), q, &out)
+}
+
+// DriverLaps devuelve las vueltas de un piloto en una carrera. Con validOnly
+// se omiten las vueltas sin tiempo o sin alguno de los sectores.
+func (c *Client) DriverLaps(sessionKey, driverNumber int, validOnly bool) (*DriverLaps, error) {
+	var out DriverLaps
+	q := url.Values{}
+	if validOnly {
+		q.Set("valid_only", "true")
+	}
+	return &out, c.get(fmt.Sprintf("/races/%d/drivers/%d/laps", sessionKey, driverNumber), q, &out)
+}
+
+// DriverSeason devuelve la temporada de un piloto fecha por fecha.
+func (c *Client) DriverSeason(driverNumber, year int) (*DriverSeason, error) {
+	var out DriverSeason
+	q := url.Values{}
+	q.Set("year", strconv.Itoa(year))
+	return &out, c.get("/drivers/"+strconv.Itoa(driverNumber)+"/season", q, &out)
+}
+
+// Teammates compara a los pilotos de un equipo. team es el nombre del equipo
+// o su version en minusculas con guiones, por ejemplo "red-bull-racing".
+func (c *Client) Teammates(team string, year int) (*TeamTeammates, error) {
+	var out TeamTeammates
+	q := url.Values{}
+	q.Set("year", strconv.Itoa(year))
+	return &out, c.get("/teams/"+url.PathEscape(team)+"/teammates", q, &out)
+}
+
+// TeamReliability devuelve abandonos y fiabilidad de cada equipo en un año.
+func (c *Client) TeamReliability(year int) (*TeamReliabilityReport, error) {
+	var out TeamReliabilityReport
+	q := url.Values{}
+	q.Set("year", strconv.Itoa(year))
+	return &out, c.get("/teams/reliability", q, &out)
+}
+
+// SeasonSummary devuelve el top 3 de cada ranking de la temporada. Con
+// includeTies tambien vienen los empatados en el tercer puesto.
+func (c *Client) SeasonSummary(year int, includeTies bool) (*SeasonSummary, error) {
+	var out SeasonSummary
+	q := url.Values{}
+	if includeTies {
+		q.Set("include_ties", "true")
+	}
+	return &out, c.get("/seasons/"+strconv.Itoa(year)+"/summary", q, &out)
+}
+
+// Metrics devuelve las metricas que acepta Leaderboard.
+func (c *Client) Metrics() (*MetricList, error) {
+	var out MetricList
+	return &out, c.get("/stats/metrics", nil, &out)
+}
+
+// Leaderboard devuelve el ranking de una metrica. scope es ScopeSeason (con
+// year) o ScopeCareer (year se ignora). Con includeTies tambien vienen los
+// empatados en el ultimo puesto.
+func (c *Client) Leaderboard(metric, scope string, year, limit int, includeTies bool) (*Leaderboard, error) {
+	var out Leaderboard
+	q := url.Values{}
+	q.Set("metric", metric)
+	q.Set("scope", scope)
+	q.Set("year", strconv.Itoa(year))
+	q.Set("limit", strconv.Itoa(limit))
+	if includeTies {
+		q.Set("include_ties", "true")
+	}
+	return &out, c.get("/stats", q, &out)
+}
+
+func (c *Client) get(ruta string, q url.Values, destino interface{}) error {
+	u := c.BaseURL + ruta
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var cuerpo struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&cuerpo) == nil {
+			apiErr.Message = cuerpo.Error
+		}
+		return apiErr
+	}
+	if err := json.NewDecoder(resp.Body).Decode(destino); err != nil {
+		return fmt.Errorf("decodificando respuesta de %s: %w", ruta, err)
+	}
+	return nil
+}
@@ -0,0 +1,648 @@
+package client
+
+// Tipos de respuesta de /api/v2. El server los serializa tal cual, asi que
+// cualquier cambio aca cambia la API.
+
+type Driver struct {
+	DriverNumber int    `json:"driver_number"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	NameAcronym  string `json:"name_acronym"`
+	TeamName     string `json:"team_name"`
+	CountryCode  string `json:"country_code"`
+}
+
+// Pagination describe la pagina devuelta por los listados.
+type Pagination struct {
+	Total      int  `json:"total"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset"`
+}
+
+type DriverList struct {
+	Data       []Driver   `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Race es una carrera o un sprint; SessionName dice cual ("Race" o "Sprint").
+type Race struct {
+	SessionKey       int    `json:"session_key"`
+	SessionName      string `json:"session_name"`
+	CountryName      string `json:"country_name"`
+	DateStart        string `json:"date_start"`
+	Year             int    `json:"year"`
+	CircuitShortName string `json:"circuit_short_name"`
+}
+
+type RaceList struct {
+	Data       []Race     `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Meeting es un gran premio: el fin de semana que agrupa practicas,
+// clasificacion, sprint y carrera.
+type Meeting struct {
+	MeetingKey       int    `json:"meeting_key"`
+	MeetingName      string `json:"meeting_name"`
+	OfficialName     string `json:"official_name"`
+	CountryName      string `json:"country_name"`
+	Location         string `json:"location"`
+	CircuitShortName string `json:"circuit_short_name"`
+	DateStart        string `json:"date_start"`
+	Year             int    `json:"year"`
+}
+
+type MeetingList struct {
+	Data       []Meeting  `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// WeekendSession es una sesion del fin de semana. En carreras y sprints Top3
+// es el podio de la clasificacion; en el resto, las tres primeras posiciones.
+// FastestLap es null si la sesion no tiene vueltas cargadas.
+type WeekendSession struct {
+	SessionKey  int                   `json:"session_key"`
+	SessionName string                `json:"session_name"`
+	SessionType string                `json:"session_type"`
+	DateStart   string                `json:"date_start"`
+	Top3        []ClassificationEntry `json:"top_3"`
+	FastestLap  *FastestLap           `json:"fastest_lap"`
+}
+
+// MeetingDetail es el fin de semana completo con las sesiones en orden.
+type MeetingDetail struct {
+	Meeting
+	Sessions []WeekendSession `json:"sessions"`
+}
+
+type RaceResult struct {
+	SessionKey       int     `json:"session_key"`
+	CircuitShortName string  `json:"circuit_short_name"`
+	Race             string  `json:"race"`
+	Position         int     `json:"position"`
+	Status           string  `json:"status"`
+	FastestLap       bool    `json:"fastest_lap"`
+	MaxSpeed         float64 `json:"max_speed"`
+	BestLapDuration  float64 `json:"best_lap_duration"`
+	LapsLed          int     `json:"laps_led"`
+}
+
+// PerformanceSummary resume las carreras de un piloto. ReliabilityRate es la
+// fraccion de carreras largadas que termino (sin contar abandonos) y es null
+// si no largo ninguna. Los abandonos incluyen choques, no solo fallas. Los
+// sprints no cuentan en victorias, podios, fiabilidad ni vueltas lideradas;
+// tienen sus propios campos.
+type PerformanceSummary struct {
+	Wins            int      `json:"wins"`
+	Top3Finishes    int      `json:"top_3_finishes"`
+	MaxSpeed        float64  `json:"max_speed"`
+	RacesStarted    int      `json:"races_started"`
+	DNFs            int      `json:"dnfs"`
+	ReliabilityRate *float64 `json:"reliability_rate"`
+	Points          int      `json:"points"`
+	SprintWins      int      `json:"sprint_wins"`
+	SprintPoints    int      `json:"sprint_points"`
+	LapsLed         int      `json:"laps_led"`
+}
+
+// DriverDetail separa los resultados de las carreras de los de los sprints.
+type DriverDetail struct {
+	DriverNumber       int                `json:"driver_number"`
+	PerformanceSummary PerformanceSummary `json:"performance_summary"`
+	RaceResults        []RaceResult       `json:"race_results"`
+	SprintResults      []RaceResult       `json:"sprint_results"`
+}
+
+// TeammateRound es el resultado del compañero de equipo en la misma fecha.
+// Ahead es true si el piloto termino adelante de su compañero.
+type TeammateRound struct {
+	DriverNumber     int    `json:"driver_number"`
+	Driver           string `json:"driver"`
+	Position         *int   `json:"position"`
+	Points           int    `json:"points"`
+	CumulativePoints int    `json:"cumulative_points"`
+	Ahead            bool   `json:"ahead"`
+}
+
+// SeasonRound es una fecha del campeonato. Points incluye los del sprint del
+// mismo fin de semana (SprintPoints). Position es null si el piloto no corrio
+// y ChampionshipPosition es su puesto en el campeonato despues de la fecha;
+// los pilotos empatados en puntos comparten el puesto.
+type SeasonRound struct {
+	Round                int            `json:"round"`
+	SessionKey           int            `json:"session_key"`
+	CircuitShortName     string         `json:"circuit_short_name"`
+	DateStart            string         `json:"date_start"`
+	Position             *int           `json:"position"`
+	Status               string         `json:"status"`
+	Points               int            `json:"points"`
+	SprintPoints         int            `json:"sprint_points"`
+	CumulativePoints     int            `json:"cumulative_points"`
+	ChampionshipPosition int            `json:"championship_position"`
+	Teammate             *TeammateRound `json:"teammate"`
+}
+
+// DriverSeason es la temporada de un piloto fecha por fecha, en orden.
+// TeammateHeadToHead cuenta en A las fechas en que el piloto termino adelante
+// de su compañero y en B las que termino atras.
+type DriverSeason struct {
+	DriverNumber         int             `json:"driver_number"`
+	Driver               string          `json:"driver"`
+	TeamName             string          `json:"team_name"`
+	Year                 int             `json:"year"`
+	Points               int             `json:"points"`
+	ChampionshipPosition int             `json:"championship_position"`
+	TeammateHeadToHead   HeadToHeadCount `json:"teammate_head_to_head"`
+	Rounds               []SeasonRound   `json:"rounds"`
+}
+
+type ClassificationEntry struct {
+	Position     int    `json:"position"`
+	DriverNumber int    `json:"driver_number"`
+	Driver       string `json:"driver"`
+	TeamName     string `json:"team_name"`
+	CountryCode  string `json:"country_code"`
+}
+
+// Nombres de sesion de las carreras. En OpenF1 los sprints tambien son de
+// tipo "Race"; se distinguen por el nombre.
+const (
+	SessionRace   = "Race"
+	SessionSprint = "Sprint"
+)
+
+// Alcances del ranking de estadisticas: una temporada o todas las cargadas.
+const (
+	ScopeSeason = "season"
+	ScopeCareer = "career"
+)
+
+// Estados de un piloto al final de la carrera. Los doblados que terminaron
+// tienen "+1 Lap", "+2 Laps", etc.
+const (
+	StatusFinished = "Finished"
+	StatusDNF      = "DNF"
+	StatusDSQ      = "DSQ"
+	StatusDNS      = "DNS"
+)
+
+// ResultEntry es una fila de la clasificacion completa. GapToWinner es null
+// para el ganador, los doblados y los que no terminaron.
+type ResultEntry struct {
+	ClassificationEntry
+	Status        string   `json:"status"`
+	LapsCompleted int      `json:"laps_completed"`
+	GapToWinner   *float64 `json:"gap_to_winner"`
+	Points        int      `json:"points"`
+	FastestLap    bool     `json:"fastest_lap"`
+}
+
+type FastestLap struct {
+	Driver      string  `json:"driver"`
+	LapDuration float64 `json:"lap_duration"`
+	Sector1     float64 `json:"sector_1"`
+	Sector2     float64 `json:"sector_2"`
+	Sector3     float64 `json:"sector_3"`
+}
+
+// PurpleSector es el mejor tiempo de la carrera en un sector y quien lo hizo.
+type PurpleSector struct {
+	DriverNumber int     `json:"driver_number"`
+	Driver       string  `json:"driver"`
+	Duration     float64 `json:"duration"`
+}
+
+// DriverIdealLap es la suma de los mejores sectores de un piloto, aunque los
+// haya hecho en vueltas distintas.
+type DriverIdealLap struct {
+	DriverNumber int     `json:"driver_number"`
+	Driver       string  `json:"driver"`
+	Sector1      float64 `json:"sector_1"`
+	Sector2      float64 `json:"sector_2"`
+	Sector3      float64 `json:"sector_3"`
+	IdealLap     float64 `json:"ideal_lap"`
+	BestLap      float64 `json:"best_lap"`
+}
+
+// IdealLap es la vuelta teorica de la carrera armada con los sectores morados.
+type IdealLap struct {
+	LapDuration float64          `json:"lap_duration"`
+	Sector1     PurpleSector     `json:"sector_1"`
+	Sector2     PurpleSector     `json:"sector_2"`
+	Sector3     PurpleSector     `json:"sector_3"`
+	Drivers     []DriverIdealLap `json:"drivers"`
+}
+
+type TyreStint struct {
+	StintNumber    int    `json:"stint_number"`
+	Compound       string `json:"compound"`
+	LapStart       int    `json:"lap_start"`
+	LapEnd         int    `json:"lap_end"`
+	TyreAgeAtStart int    `json:"tyre_age_at_start"`
+}
+
+// PitStop es una parada en boxes. PitDuration es el tiempo total en el pit
+// lane y es null cuando OpenF1 no lo informa.
+type PitStop struct {
+	LapNumber   int      `json:"lap_number"`
+	PitDuration *float64 `json:"pit_duration"`
+}
+
+// DriverStrategy son los stints y paradas de un piloto en una carrera.
+type DriverStrategy struct {
+	DriverNumber int         `json:"driver_number"`
+	Driver       string      `json:"driver"`
+	Stints       []TyreStint `json:"stints"`
+	PitStops     []PitStop   `json:"pit_stops"`
+}
+
+// WeatherSummary resume las mediciones de clima de una sesion.
+type WeatherSummary struct {
+	AirTemperatureMin   float64 `json:"air_temperature_min"`
+	AirTemperatureMax   float64 `json:"air_temperature_max"`
+	TrackTemperatureMin float64 `json:"track_temperature_min"`
+	TrackTemperatureMax float64 `json:"track_temperature_max"`
+	HumidityAvg         float64 `json:"humidity_avg"`
+	Rainfall            bool    `json:"rainfall"`
+}
+
+type MaxSpeed struct {
+	Driver   string  `json:"driver"`
+	SpeedKmh float64 `json:"speed_kmh"`
+}
+
+type RaceDetail struct {
+	SessionKey     int                   `json:"session_key"`
+	SessionName    string                `json:"session_name"`
+	Podium         []ClassificationEntry `json:"podium"`
+	LastPlace      *ClassificationEntry  `json:"last_place"`
+	Classification []ResultEntry         `json:"classification"`
+	FastestLap     FastestLap            `json:"fastest_lap"`
+	IdealLap       IdealLap              `json:"ideal_lap"`
+	MaxSpeed       MaxSpeed              `json:"max_speed"`
+	Strategy       []DriverStrategy      `json:"strategy"`
+	Weather        *WeatherSummary       `json:"weather"`
+}
+
+// SeasonStat es un piloto en un ranking. Los empatados en Value comparten
+// Position.
+type SeasonStat struct {
+	Position    int    `json:"position"`
+	Driver      string `json:"driver"`
+	Value       int    `json:"value"`
+	TeamName    string `json:"team_name"`
+	CountryCode string `json:"country_code"`
+}
+
+// SeasonSummary cuenta victorias, vueltas rapidas y puntos de las carreras;
+// los sprints van aparte en SprintWinners y SprintPoints.
+type SeasonSummary struct {
+	Season        int          `json:"season"`
+	Winners       []SeasonStat `json:"winners"`
+	FastestLaps   []SeasonStat `json:"fastest_laps"`
+	PolePositions []SeasonStat `json:"pole_positions"`
+	Points        []SeasonStat `json:"points"`
+	SprintWinners []SeasonStat `json:"sprint_winners"`
+	SprintPoints  []SeasonStat `json:"sprint_points"`
+}
+
+// Metric es una de las estadisticas disponibles en el ranking.
+type Metric struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Unit        string `json:"unit"`
+}
+
+type MetricList struct {
+	Metrics []Metric `json:"metrics"`
+}
+
+// LeaderboardEntry es un piloto en el ranking; como en SeasonStat, los
+// empatados comparten Position.
+type LeaderboardEntry struct {
+	Position     int     `json:"position"`
+	DriverNumber int     `json:"driver_number"`
+	Driver       string  `json:"driver"`
+	TeamName     string  `json:"team_name"`
+	CountryCode  string  `json:"country_code"`
+	Value        float64 `json:"value"`
+}
+
+// Leaderboard es el ranking de pilotos en una metrica, de una temporada
+// (Scope "season") o de todas las cargadas (Scope "career", Year en null).
+type Leaderboard struct {
+	Metric  Metric             `json:"metric"`
+	Scope   string             `json:"scope"`
+	Year    *int               `json:"year"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+type HeadToHeadDriver struct {
+	DriverNumber int    `json:"driver_number"`
+	Driver       string `json:"driver"`
+	TeamName     string `json:"team_name"`
+}
+
+// HeadToHeadCount cuenta cuantas veces termino adelante cada piloto. Los
+// empates y las sesiones sin posicion de alguno no suman.
+type HeadToHeadCount struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+// HeadToHeadRace es una carrera que largaron ambos pilotos. Los deltas son
+// A - B: un delta negativo de vuelta significa que A fue mas rapido.
+type HeadToHeadRace struct {
+	SessionKey       int      `json:"session_key"`
+	CircuitShortName string   `json:"circuit_short_name"`
+	DateStart        string   `json:"date_start"`
+	PositionA        int      `json:"position_a"`
+	PositionB        int      `json:"position_b"`
+	Ahead            int      `json:"ahead"` // driver_number del que termino adelante, 0 si empataron o falta una posicion
+	QualifyingA      *int     `json:"qualifying_a"`
+	QualifyingB      *int     `json:"qualifying_b"`
+	BestLapA         float64  `json:"best_lap_a"`
+	BestLapB         float64  `json:"best_lap_b"`
+	BestLapDelta     *float64 `json:"best_lap_delta"`
+	TopSpeedA        float64  `json:"top_speed_a"`
+	TopSpeedB        float64  `json:"top_speed_b"`
+	TopSpeedDelta    float64  `json:"top_speed_delta"`
+}
+
+type HeadToHead struct {
+	DriverA              HeadToHeadDriver `json:"driver_a"`
+	DriverB              HeadToHeadDriver `json:"driver_b"`
+	RacesBothStarted     int              `json:"races_both_started"`
+	RaceHeadToHead       HeadToHeadCount  `json:"race_head_to_head"`
+	QualifyingHeadToHead HeadToHeadCount  `json:"qualifying_head_to_head"`
+	AvgBestLapDelta      *float64         `json:"avg_best_lap_delta"`
+	AvgTopSpeedDelta     *float64         `json:"avg_top_speed_delta"`
+	Races                []HeadToHeadRace `json:"races"`
+}
+
+// Estados de pista de una vuelta, de menor a mayor gravedad. Una vuelta toma
+// el estado mas grave de los que hubo mientras se corria.
+const (
+	TrackGreen  = "GREEN"
+	TrackYellow = "YELLOW"
+	TrackVSC    = "VSC"
+	TrackSC     = "SC"
+	TrackRed    = "RED"
+)
+
+// Lap es una vuelta de un piloto. Una vuelta es valida si tiene tiempo y los
+// tres sectores; las deltas solo se calculan para vueltas validas. Neutralised
+// indica que se corrio bajo VSC, safety car o bandera roja.
+type Lap struct {
+	LapNumber           int      `json:"lap_number"`
+	DateStart           string   `json:"date_start"`
+	LapDuration         float64  `json:"lap_duration"`
+	Sector1             float64  `json:"sector_1"`
+	Sector2             float64  `json:"sector_2"`
+	Sector3             float64  `json:"sector_3"`
+	StSpeed             float64  `json:"st_speed"`
+	Valid               bool     `json:"valid"`
+	DeltaToPersonalBest *float64 `json:"delta_to_personal_best"`
+	DeltaToSessionBest  *float64 `json:"delta_to_session_best"`
+	TrackStatus         string   `json:"track_status"`
+	Neutralised         bool     `json:"neutralised"`
+}
+
+type DriverLaps struct {
+	SessionKey   int      `json:"session_key"`
+	DriverNumber int      `json:"driver_number"`
+	PersonalBest *float64 `json:"personal_best"`
+	SessionBest  *float64 `json:"session_best"`
+	Laps         []Lap    `json:"laps"`
+}
+
+// PaceStint es un tramo de vueltas limpias consecutivas. Los stints se
+// aproximan cortando en las vueltas lentas (entrada y salida de boxes, safety
+// car), asi que la degradacion incluye tambien el efecto del combustible.
+type PaceStint struct {
+	StartLap          int      `json:"start_lap"`
+	EndLap            int      `json:"end_lap"`
+	Laps              int      `json:"laps"`
+	DegradationPerLap *float64 `json:"degradation_per_lap"`
+}
+
+// DriverPace es el ritmo de un piloto en sus vueltas limpias. Sin vueltas
+// limpias las medias quedan en 0 y GapToWinnerPct en null, igual que si el
+// ganador no tiene vueltas limpias.
+type DriverPace struct {
+	DriverNumber      int         `json:"driver_number"`
+	Driver            string      `json:"driver"`
+	TeamName          string      `json:"team_name"`
+	Position          *int        `json:"position"`
+	CleanLaps         int         `json:"clean_laps"`
+	NeutralisedLaps   int         `json:"neutralised_laps"`
+	MedianLap         float64     `json:"median_lap"`
+	MeanLap           float64     `json:"mean_lap"`
+	StdDev            float64     `json:"std_dev"`
+	DegradationPerLap *float64    `json:"degradation_per_lap"`
+	GapToWinnerPct    *float64    `json:"gap_to_winner_pct"`
+	Stints            []PaceStint `json:"stints"`
+}
+
+type RacePace struct {
+	SessionKey      int          `json:"session_key"`
+	WinnerMedianLap *float64     `json:"winner_median_lap"`
+	Drivers         []DriverPace `json:"drivers"`
+}
+
+// PracticeRun es una tanda de vueltas rapidas seguidas en una practica. Las
+// tandas largas (LongRun) son simulaciones de carrera; las cortas, de
+// clasificacion. Compound esta vacio si no hay stints cargados.
+type PracticeRun struct {
+	StartLap          int      `json:"start_lap"`
+	EndLap            int      `json:"end_lap"`
+	Laps              int      `json:"laps"`
+	Compound          string   `json:"compound"`
+	BestLap           float64  `json:"best_lap"`
+	MedianLap         float64  `json:"median_lap"`
+	DegradationPerLap *float64 `json:"degradation_per_lap"`
+	LongRun           bool     `json:"long_run"`
+}
+
+// DriverPractice resume la practica de un piloto. ShortRunPace es su mejor
+// vuelta en tandas cortas y LongRunPace la mediana de sus tandas largas; son
+// null si no hizo tandas de ese tipo. RacePosition y RaceStatus son el
+// resultado de la carrera del mismo fin de semana, si ya esta cargada.
+type DriverPractice struct {
+	DriverNumber int           `json:"driver_number"`
+	Driver       string        `json:"driver"`
+	TeamName     string        `json:"team_name"`
+	Position     int           `json:"position"`
+	TotalLaps    int           `json:"total_laps"`
+	BestLap      float64       `json:"best_lap"`
+	CleanLaps    int           `json:"clean_laps"`
+	CleanLapPace float64       `json:"clean_lap_pace"`
+	ShortRunPace *float64      `json:"short_run_pace"`
+	LongRunPace  *float64      `json:"long_run_pace"`
+	LongRunLaps  int           `json:"long_run_laps"`
+	RacePosition *int          `json:"race_position"`
+	RaceStatus   string        `json:"race_status"`
+	Runs         []PracticeRun `json:"runs"`
+}
+
+// PracticeAnalysis es el analisis de una practica libre, ordenado por mejor
+// vuelta. RaceSessionKey es la carrera del mismo gran premio.
+type PracticeAnalysis struct {
+	SessionKey     int              `json:"session_key"`
+	SessionName    string           `json:"session_name"`
+	MeetingKey     *int             `json:"meeting_key"`
+	RaceSessionKey *int             `json:"race_session_key"`
+	Drivers        []DriverPractice `json:"drivers"`
+}
+
+// GapPoint es la diferencia de un piloto al terminar una vuelta. GapToLeader e
+// Interval son null si estaba a una o mas vueltas del lider (ver LapsBehind)
+// o si no hay datos para esa vuelta.
+type GapPoint struct {
+	LapNumber   int      `json:"lap_number"`
+	Date        string   `json:"date"`
+	GapToLeader *float64 `json:"gap_to_leader"`
+	Interval    *float64 `json:"interval"`
+	LapsBehind  int      `json:"laps_behind"`
+}
+
+type DriverGaps struct {
+	DriverNumber int        `json:"driver_number"`
+	Driver       string     `json:"driver"`
+	Points       []GapPoint `json:"points"`
+}
+
+type RaceGaps struct {
+	SessionKey int          `json:"session_key"`
+	Drivers    []DriverGaps `json:"drivers"`
+}
+
+// LapLeader es el piloto que iba primero al terminar una vuelta.
+type LapLeader struct {
+	LapNumber    int    `json:"lap_number"`
+	DriverNumber int    `json:"driver_number"`
+	Driver       string `json:"driver"`
+}
+
+type LapsLed struct {
+	DriverNumber int    `json:"driver_number"`
+	Driver       string `json:"driver"`
+	TeamName     string `json:"team_name"`
+	LapsLed      int    `json:"laps_led"`
+}
+
+// RaceLeaders tiene el lider de cada vuelta y las vueltas lideradas por
+// piloto, de mas a menos.
+type RaceLeaders struct {
+	SessionKey int         `json:"session_key"`
+	Laps       []LapLeader `json:"laps"`
+	Drivers    []LapsLed   `json:"drivers"`
+}
+
+// TelemetryPoint es una muestra de telemetria. Time son los segundos desde el
+// inicio de la vuelta y Distance los metros recorridos, integrando la velocidad.
+type TelemetryPoint struct {
+	Time     float64 `json:"time"`
+	Distance float64 `json:"distance"`
+	Speed    float64 `json:"speed"`
+	Throttle float64 `json:"throttle"`
+	Brake    int     `json:"brake"`
+	Gear     int     `json:"gear"`
+	RPM      int     `json:"rpm"`
+	DRS      int     `json:"drs"`
+}
+
+// LapTelemetry es la telemetria de una vuelta reducida a lo sumo a la cantidad
+// de puntos pedida. Samples y MaxSpeed se calculan sobre todas las muestras.
+type LapTelemetry struct {
+	SessionKey   int              `json:"session_key"`
+	DriverNumber int              `json:"driver_number"`
+	LapNumber    int              `json:"lap_number"`
+	Samples      int              `json:"samples"`
+	MaxSpeed     float64          `json:"max_speed"`
+	Points       []TelemetryPoint `json:"points"`
+}
+
+type LapRef struct {
+	DriverNumber int     `json:"driver_number"`
+	Driver       string  `json:"driver"`
+	LapNumber    int     `json:"lap_number"`
+	LapDuration  float64 `json:"lap_duration"`
+}
+
+// LapComparisonPoint compara dos vueltas en la misma distancia. Delta es el
+// tiempo acumulado de B menos el de A: positivo si B va perdiendo.
+type LapComparisonPoint struct {
+	Distance  float64 `json:"distance"`
+	SpeedA    float64 `json:"speed_a"`
+	SpeedB    float64 `json:"speed_b"`
+	ThrottleA float64 `json:"throttle_a"`
+	ThrottleB float64 `json:"throttle_b"`
+	BrakeA    int     `json:"brake_a"`
+	BrakeB    int     `json:"brake_b"`
+	Delta     float64 `json:"delta"`
+}
+
+// LapComparison alinea dos vueltas por distancia. La distancia de B se escala
+// al largo de la vuelta de A (LapLength), porque al integrar la velocidad cada
+// vuelta da un largo un poco distinto.
+type LapComparison struct {
+	SessionKey int                  `json:"session_key"`
+	A          LapRef               `json:"a"`
+	B          LapRef               `json:"b"`
+	LapLength  float64              `json:"lap_length"`
+	Points     []LapComparisonPoint `json:"points"`
+}
+
+// TeammatePair compara a dos pilotos que corrieron juntos en un equipo. Los
+// conteos y diferencias son de A contra B: una diferencia negativa de vuelta
+// significa que A fue mas rapido. Las diferencias son null si no hay sesiones
+// con vuelta valida de ambos.
+type TeammatePair struct {
+	DriverA              HeadToHeadDriver `json:"driver_a"`
+	DriverB              HeadToHeadDriver `json:"driver_b"`
+	RacesTogether        int              `json:"races_together"`
+	RaceHeadToHead       HeadToHeadCount  `json:"race_head_to_head"`
+	QualifyingHeadToHead HeadToHeadCount  `json:"qualifying_head_to_head"`
+	AvgQualifyingGap     *float64         `json:"avg_qualifying_gap"`
+	AvgRaceBestLapGap    *float64         `json:"avg_race_best_lap_gap"`
+	PointsA              int              `json:"points_a"`
+	PointsB              int              `json:"points_b"`
+}
+
+// TeamDriverPoints son los puntos (carreras y sprints) que sumo un piloto
+// corriendo para el equipo y su parte del total del equipo.
+type TeamDriverPoints struct {
+	DriverNumber int     `json:"driver_number"`
+	Driver       string  `json:"driver"`
+	Races        int     `json:"races"`
+	Points       int     `json:"points"`
+	Share        float64 `json:"share"`
+}
+
+// TeamTeammates es el analisis de compañeros de un equipo en una temporada,
+// con una pareja por cada par de pilotos que corrio junto.
+type TeamTeammates struct {
+	TeamName string             `json:"team_name"`
+	Year     int                `json:"year"`
+	Points   int                `json:"points"`
+	Drivers  []TeamDriverPoints `json:"drivers"`
+	Pairs    []TeammatePair     `json:"pairs"`
+}
+
+type TeamReliability struct {
+	TeamName        string  `json:"team_name"`
+	Starts          int     `json:"starts"`
+	Finishes        int     `json:"finishes"`
+	DNFs            int     `json:"dnfs"`
+	DSQs            int     `json:"dsqs"`
+	ReliabilityRate float64 `json:"reliability_rate"`
+}
+
+// TeamReliabilityReport cuenta las largadas de los dos autos de cada equipo en
+// una temporada, de mayor a menor fiabilidad.
+type TeamReliabilityReport struct {
+	Year  int               `json:"year"`
+	Teams []TeamReliability `json:"teams"`
+}
@@ -0,0 +1,1384 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"tarea1sd/client"
+	"tarea1sd/render"
+	"tarea1sd/tui"
+)
+
+const baseURL = "http://10.10.28.60:8080/api/v2"
+
+// Cantidad de filas por pagina en los listados.
+const tamPagina = 10
+
+var api = client.New(baseURL)
+
+// Formato en el que se imprimen los resultados, elegido con --output.
+var formato = render.Table
+
+// comando es una consulta que tambien se puede ejecutar sin el menu, por
+// ejemplo: go run ./cmd/cliente carrera 9472 --output json
+type comando struct {
+	nombre string
+	uso    string
+	correr func(args []string) error
+}
+
+var comandos = []comando{
+	{"corredores", "corredores", func(args []string) error {
+		return listarCorredores()
+	}},
+	{"corredor", "corredor <número>", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el número del piloto")
+		}
+		return verDetalleCorredor(args[0])
+	}},
+	{"temporada-corredor", "temporada-corredor <número> [año]", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el número del piloto")
+		}
+		year := "2024"
+		if len(args) > 1 {
+			year = args[1]
+		}
+		return verTemporadaCorredor(args[0], year)
+	}},
+	{"comparar", "comparar <número> <número>", func(args []string) error {
+		if len(args) < 2 {
+			return fmt.Errorf("faltan los números de los dos pilotos")
+		}
+		return verComparacion(args[0], args[1])
+	}},
+	{"carreras", "carreras [sprint|todas]", func(args []string) error {
+		tipo := ""
+		if len(args) > 0 {
+			tipo = args[0]
+		}
+		return listarCarreras(tipo)
+	}},
+	{"carrera", "carrera <id>", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el ID de la carrera")
+		}
+		return verDetalleCarrera(args[0])
+	}},
+	{"gps", "gps [año]", func(args []string) error {
+		year := "2024"
+		if len(args) > 0 {
+			year = args[0]
+		}
+		return listarGrandesPremios(year)
+	}},
+	{"gp", "gp <id gran premio>", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el ID del gran premio")
+		}
+		return verGranPremio(args[0], nil)
+	}},
+	{"practica", "practica <id sesión>", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el ID de la sesión de práctica")
+		}
+		return verPractica(args[0])
+	}},
+	{"ritmo", "ritmo <id carrera>", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el ID de la carrera")
+		}
+		return verRitmo(args[0])
+	}},
+	{"diferencias", "diferencias <id carrera> [número...]", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el ID de la carrera")
+		}
+		return verDiferencias(args[0], args[1:])
+	}},
+	{"lideres", "lideres <id carrera>", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el ID de la carrera")
+		}
+		return verLideres(args[0])
+	}},
+	{"vueltas", "vueltas <id carrera> <número> [validas]", func(args []string) error {
+		if len(args) < 2 {
+			return fmt.Errorf("faltan el ID de la carrera y el número del piloto")
+		}
+		return verVueltas(args[0], args[1], len(args) > 2 && args[2] == "validas")
+	}},
+	{"telemetria", "telemetria <id carrera> <número> <vuelta> [puntos]", func(args []string) error {
+		if len(args) < 3 {
+			return fmt.Errorf("faltan el ID de la carrera, el número del piloto y la vuelta")
+		}
+		puntos := "0"
+		if len(args) > 3 {
+			puntos = args[3]
+		}
+		return verTelemetria(args[0], args[1], args[2], puntos)
+	}},
+	{"comparar-vuelta", "comparar-vuelta <id carrera> <número:vuelta> <número:vuelta> [puntos]", func(args []string) error {
+		if len(args) < 3 {
+			return fmt.Errorf("faltan el ID de la carrera y las dos vueltas, por ejemplo comparar-vuelta 9472 1:45 16:44")
+		}
+		puntos := "0"
+		if len(args) > 3 {
+			puntos = args[3]
+		}
+		return verComparacionVueltas(args[0], args[1], args[2], puntos)
+	}},
+	{"temporada", "temporada [año]", func(args []string) error {
+		year := "2024"
+		if len(args) > 0 {
+			year = args[0]
+		}
+		return verResumenTemporada(year)
+	}},
+	{"fiabilidad", "fiabilidad [año]", func(args []string) error {
+		year := "2024"
+		if len(args) > 0 {
+			year = args[0]
+		}
+		return verFiabilidad(year)
+	}},
+	{"companeros", "companeros <equipo> [año]", func(args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("falta el equipo")
+		}
+		// El nombre del equipo puede venir en varias palabras.
+		year := "2024"
+		if _, err := strconv.Atoi(args[len(args)-1]); err == nil && len(args) > 1 {
+			year, args = args[len(args)-1], args[:len(args)-1]
+		}
+		return verCompaneros(strings.Join(args, " "), year)
+	}},
+	{"estadisticas", "estadisticas [métrica] [año|historico] [cantidad]", func(args []string) error {
+		if len(args) < 1 {
+			return listarMetricas()
+		}
+		alcance, cantidad := "2024", "10"
+		if len(args) > 1 {
+			alcance = args[1]
+		}
+		if len(args) > 2 {
+			cantidad = args[2]
+		}
+		return verEstadisticas(args[0], alcance, cantidad)
+	}},
+}
+
+func main() {
+	fs := flag.NewFlagSet("cliente", flag.ExitOnError)
+	salida := fs.String("output", string(render.Table), "formato de salida: table, json, csv o markdown")
+	fs.StringVar(salida, "o", string(render.Table), "abreviatura de --output")
+	pantallaCompleta := fs.Bool("tui", false, "abre la interfaz de pantalla completa en vez del menú")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: go run ./cmd/cliente [--tui] [comando] [--output table|json|csv|markdown]")
+		fmt.Fprintln(os.Stderr, "Sin comando se abre el menú. Comandos:")
+		for _, c := range comandos {
+			fmt.Fprintln(os.Stderr, "  "+c.uso)
+		}
+	}
+	flags, args := separarFlags(os.Args[1:])
+	fs.Parse(flags)
+	f, err := render.ParseFormato(*salida)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	formato = f
+
+	if len(args) == 0 {
+		if *pantallaCompleta {
+			err := tui.Ejecutar(api)
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, tui.ErrNoDisponible) {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(err.Error() + "; se usa el menú de texto.")
+		}
+		menu()
+		return
+	}
+	for _, c := range comandos {
+		if c.nombre == args[0] {
+			if err := c.correr(args[1:]); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Comando desconocido: %s\n", args[0])
+	fs.Usage()
+	os.Exit(2)
+}
+
+// separarFlags permite escribir las opciones antes o despues del comando;
+// el paquete flag deja de leer opciones en el primer argumento posicional.
+func separarFlags(args []string) (flags, posicionales []string) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") || a == "-" {
+			posicionales = append(posicionales, a)
+			continue
+		}
+		flags = append(flags, a)
+		if !strings.Contains(a, "=") && i+1 < len(args) && !esFlagBooleano(a) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return flags, posicionales
+}
+
+func esFlagBooleano(a string) bool {
+	switch strings.TrimLeft(a, "-") {
+	case "h", "help", "tui":
+		return true
+	}
+	return false
+}
+
+func menu() {
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for {
+		fmt.Println(`
+Menu
+1. Ver corredores
+2. Ver detalle de corredor
+3. Ver carreras
+4. Ver detalle de carrera
+5. Resumen de temporada
+6. Comparar corredores
+7. Vueltas de un corredor en una carrera
+8. Ritmo de carrera
+9. Diferencias en carrera
+10. Fiabilidad de equipos
+11. Fin de semana de un gran premio
+12. Temporada de un corredor
+13. Compañeros de un equipo
+14. Estadísticas
+15. Salir`)
+		fmt.Print("Seleccione una opción: ")
+		scanner.Scan()
+		opcion := scanner.Text()
+
+		var err error
+		switch opcion {
+		case "1":
+			err = verCorredores(scanner)
+		case "2":
+			fmt.Print("Ingrese el número del piloto: ")
+			scanner.Scan()
+			num := scanner.Text()
+			err = verDetalleCorredor(num)
+		case "3":
+			err = verCarreras(scanner)
+		case "4":
+			fmt.Print("Ingrese el ID de la carrera: ")
+			scanner.Scan()
+			num := scanner.Text()
+			err = verDetalleCarrera(num)
+		case "5":
+			err = verResumenTemporada("2024")
+		case "6":
+			fmt.Print("Ingrese el número del primer piloto: ")
+			scanner.Scan()
+			a := scanner.Text()
+			fmt.Print("Ingrese el número del segundo piloto: ")
+			scanner.Scan()
+			b := scanner.Text()
+			err = verComparacion(a, b)
+		case "7":
+			fmt.Print("Ingrese el ID de la carrera: ")
+			scanner.Scan()
+			carrera := scanner.Text()
+			fmt.Print("Ingrese el número del piloto: ")
+			scanner.Scan()
+			num := scanner.Text()
+			err = verVueltas(carrera, num, false)
+		case "8":
+			fmt.Print("Ingrese el ID de la carrera: ")
+			scanner.Scan()
+			num := scanner.Text()
+			err = verRitmo(num)
+		case "9":
+			fmt.Print("Ingrese el ID de la carrera: ")
+			scanner.Scan()
+			num := scanner.Text()
+			fmt.Print("Ingrese los números de piloto separados por espacio (Enter para todos): ")
+			scanner.Scan()
+			err = verDiferencias(num, strings.Fields(scanner.Text()))
+		case "10":
+			err = verFiabilidad("2024")
+		case "11":
+			if err = listarGrandesPremios("2024"); err != nil {
+				break
+			}
+			fmt.Print("Ingrese el ID del gran premio: ")
+			scanner.Scan()
+			err = verGranPremio(scanner.Text(), scanner)
+		case "12":
+			fmt.Print("Ingrese el número del piloto: ")
+			scanner.Scan()
+			err = verTemporadaCorredor(scanner.Text(), "2024")
+		case "13":
+			fmt.Print("Ingrese el equipo: ")
+			scanner.Scan()
+			err = verCompaneros(scanner.Text(), "2024")
+		case "14":
+			if err = listarMetricas(); err != nil {
+				break
+			}
+			fmt.Print("Ingrese la métrica: ")
+			scanner.Scan()
+			metrica := scanner.Text()
+			fmt.Print("Ingrese el año o \"historico\" (Enter para 2024): ")
+			scanner.Scan()
+			alcance := strings.TrimSpace(scanner.Text())
+			if alcance == "" {
+				alcance = "2024"
+			}
+			err = verEstadisticas(metrica, alcance, "10")
+		case "15":
+			fmt.Println("Fin del programa.")
+			return
+		default:
+			fmt.Println("Opción inválida.")
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+}
+
+func mostrar(datos interface{}, tablas ...render.Tabla) error {
+	return render.Escribir(os.Stdout, formato, datos, tablas...)
+}
+
+func tablaCorredores(pilotos []client.Driver, desde int) render.Tabla {
+	t := render.Tabla{Columnas: []string{"#", "Nombre", "Apellido", "Nº Piloto", "Equipo", "País"}}
+	for i, p := range pilotos {
+		t.Filas = append(t.Filas, []string{
+			strconv.Itoa(desde + i + 1), p.FirstName, p.LastName, strconv.Itoa(p.DriverNumber), p.TeamName, p.CountryCode,
+		})
+	}
+	return t
+}
+
+func verCorredores(scanner *bufio.Scanner) error {
+	offset := 0
+	for {
+		pilotos, err := api.ListDrivers(client.ListOptions{Limit: tamPagina, Offset: offset})
+		if err != nil {
+			return err
+		}
+		if err := mostrar(pilotos, tablaCorredores(pilotos.Data, offset)); err != nil {
+			return err
+		}
+		var ok bool
+		if offset, ok = navegarPaginas(scanner, pilotos.Pagination); !ok {
+			return nil
+		}
+	}
+}
+
+// listarCorredores trae todas las paginas, para usar fuera del menu.
+func listarCorredores() error {
+	todos, err := api.AllDrivers(client.ListOptions{})
+	if err != nil {
+		return err
+	}
+	return mostrar(todos, tablaCorredores(todos, 0))
+}
+
+func verDetalleCorredor(id string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return fmt.Errorf("el número del piloto debe ser un número")
+	}
+	data, err := api.DriverDetail(num)
+	if err != nil {
+		return err
+	}
+	if len(data.RaceResults) == 0 && formato != render.JSON {
+		fmt.Println("No hay resultados disponibles para este piloto.")
+		return nil
+	}
+
+	resultados := render.Tabla{
+		Titulo:   fmt.Sprintf("Resultados del piloto %d", num),
+		Columnas: []string{"#", "Carrera", "Circuito", "Pos Final", "Estado", "Vueltas lideradas", "Vuelta rápida", "Velocidad máx (km/h)", "Menor tiempo vuelta (s)"},
+	}
+	filas := func(lista []client.RaceResult) [][]string {
+		var filas [][]string
+		for i, r := range lista {
+			estado := "-"
+			if r.Status != "" {
+				estado = estadoCarrera(r.Status)
+			}
+			filas = append(filas, []string{
+				strconv.Itoa(i + 1),
+				r.Race,
+				r.CircuitShortName,
+				strconv.Itoa(r.Position),
+				estado,
+				strconv.Itoa(r.LapsLed),
+				boolToStr(r.FastestLap),
+				fmt.Sprintf("%.0f", r.MaxSpeed),
+				fmt.Sprintf("%.3f", r.BestLapDuration),
+			})
+		}
+		return filas
+	}
+	resultados.Filas = filas(data.RaceResults)
+	summary := data.PerformanceSummary
+	fiabilidad := "-"
+	if summary.ReliabilityRate != nil {
+		fiabilidad = fmt.Sprintf("%.0f%%", *summary.ReliabilityRate*100)
+	}
+	resumen := render.Tabla{
+		Titulo:   "Resumen del desempeño del piloto",
+		Columnas: []string{"Carreras ganadas", "Veces en el top 3", "Puntos", "Vueltas lideradas", "Velocidad máxima alcanzada (km/h)", "Abandonos", "Fiabilidad"},
+		Filas: [][]string{{
+			strconv.Itoa(summary.Wins), strconv.Itoa(summary.Top3Finishes), strconv.Itoa(summary.Points), strconv.Itoa(summary.LapsLed), fmt.Sprintf("%.0f", summary.MaxSpeed),
+			fmt.Sprintf("%d de %d", summary.DNFs, summary.RacesStarted), fiabilidad,
+		}},
+	}
+	if len(data.SprintResults) == 0 {
+		return mostrar(data, resultados, resumen)
+	}
+	sprints := render.Tabla{
+		Titulo:   fmt.Sprintf("Sprints del piloto %d - %d ganados, %d puntos", num, summary.SprintWins, summary.SprintPoints),
+		Columnas: resultados.Columnas,
+		Filas:    filas(data.SprintResults),
+	}
+	return mostrar(data, resultados, sprints, resumen)
+}
+
+// verTemporadaCorredor muestra la temporada fecha por fecha y la evolucion
+// de posicion, puntos y puesto en el campeonato como barras.
+func verTemporadaCorredor(id, year string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return fmt.Errorf("el número del piloto debe ser un número")
+	}
+	anio, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil {
+		return fmt.Errorf("el año debe ser un número")
+	}
+	data, err := api.DriverSeason(num, anio)
+	if err != nil {
+		return err
+	}
+	if len(data.Rounds) == 0 && formato != render.JSON {
+		fmt.Println("No hay carreras cargadas para esa temporada.")
+		return nil
+	}
+
+	fechas := render.Tabla{
+		Titulo: fmt.Sprintf("Temporada %d de %s (%s) - %d puntos, %dº en el campeonato", data.Year, data.Driver, data.TeamName,
+			data.Points, data.ChampionshipPosition),
+		Columnas: []string{"Fecha", "Circuito", "Día", "Pos", "Puntos", "Sprint", "Acumulado", "Campeonato", "Compañero", "Pos compañero", "Acumulado compañero"},
+	}
+	var posiciones, puntos, campeonato []float64
+	for _, r := range data.Rounds {
+		pos := math.NaN()
+		if r.Position != nil {
+			pos = -float64(*r.Position)
+		}
+		posiciones = append(posiciones, pos)
+		puntos = append(puntos, float64(r.CumulativePoints))
+		campeonato = append(campeonato, -float64(r.ChampionshipPosition))
+
+		companero, posCompanero, acumCompanero := "-", "-", "-"
+		if t := r.Teammate; t != nil {
+			companero, posCompanero, acumCompanero = t.Driver, posicionOpcional(t.Position), strconv.Itoa(t.CumulativePoints)
+		}
+		posicion := posicionOpcional(r.Position)
+		if r.Status != "" && !strings.HasPrefix(r.Status, "+") && r.Status != client.StatusFinished {
+			posicion += " (" + r.Status + ")"
+		}
+		fechas.Filas = append(fechas.Filas, []string{
+			strconv.Itoa(r.Round), r.CircuitShortName, formatFecha(r.DateStart), posicion, strconv.Itoa(r.Points), strconv.Itoa(r.SprintPoints),
+			strconv.Itoa(r.CumulativePoints), strconv.Itoa(r.ChampionshipPosition), companero, posCompanero, acumCompanero,
+		})
+	}
+	// Las posiciones se invierten para que el primer puesto sea la barra mas alta.
+	evolucion := render.Tabla{
+		Titulo:   fmt.Sprintf("Evolución - contra su compañero terminó %d veces adelante y %d atrás", data.TeammateHeadToHead.A, data.TeammateHeadToHead.B),
+		Columnas: []string{"Serie", fmt.Sprintf("Fechas 1 a %d", len(data.Rounds))},
+		Filas: [][]string{
+			{"Posición en carrera", render.Sparkline(posiciones)},
+			{"Puntos acumulados", render.Sparkline(puntos)},
+			{"Puesto en el campeonato", render.Sparkline(campeonato)},
+		},
+	}
+	return mostrar(data, fechas, evolucion)
+}
+
+func verComparacion(idA, idB string) error {
+	a, errA := strconv.Atoi(strings.TrimSpace(idA))
+	b, errB := strconv.Atoi(strings.TrimSpace(idB))
+	if errA != nil || errB != nil {
+		return fmt.Errorf("los números de piloto deben ser números")
+	}
+	h, err := api.HeadToHead(a, b)
+	if err != nil {
+		return err
+	}
+	nombreA, nombreB := h.DriverA.Driver, h.DriverB.Driver
+
+	resumen := render.Tabla{
+		Titulo:   fmt.Sprintf("%s vs %s - %d carreras largadas por ambos", nombreA, nombreB, h.RacesBothStarted),
+		Columnas: []string{"", nombreA, nombreB},
+		Filas: [][]string{
+			{"Equipo", h.DriverA.TeamName, h.DriverB.TeamName},
+			{"Carreras adelante", strconv.Itoa(h.RaceHeadToHead.A), strconv.Itoa(h.RaceHeadToHead.B)},
+			{"Clasificaciones adelante", strconv.Itoa(h.QualifyingHeadToHead.A), strconv.Itoa(h.QualifyingHeadToHead.B)},
+		},
+	}
+	if h.AvgBestLapDelta != nil {
+		resumen.Filas = append(resumen.Filas,
+			[]string{"Diferencia promedio de mejor vuelta (s)", fmt.Sprintf("%+.3f", *h.AvgBestLapDelta), fmt.Sprintf("%+.3f", -*h.AvgBestLapDelta)})
+	}
+	if h.AvgTopSpeedDelta != nil {
+		resumen.Filas = append(resumen.Filas,
+			[]string{"Diferencia promedio de velocidad máx (km/h)", fmt.Sprintf("%+.1f", *h.AvgTopSpeedDelta), fmt.Sprintf("%+.1f", -*h.AvgTopSpeedDelta)})
+	}
+
+	carreras := render.Tabla{
+		Titulo: fmt.Sprintf("Carrera por carrera (A: %s, B: %s)", nombreA, nombreB),
+		Columnas: []string{"Circuito", "Fecha", "Pos A", "Pos B", "Adelante",
+			"Qualy A", "Qualy B", "Mejor vuelta A", "Mejor vuelta B", "Δ vuelta (s)", "Vel máx A", "Vel máx B", "Δ vel (km/h)"},
+	}
+	for _, r := range h.Races {
+		adelante := "-"
+		switch r.Ahead {
+		case h.DriverA.DriverNumber:
+			adelante = nombreA
+		case h.DriverB.DriverNumber:
+			adelante = nombreB
+		}
+		delta := "-"
+		if r.BestLapDelta != nil {
+			delta = fmt.Sprintf("%+.3f", *r.BestLapDelta)
+		}
+		carreras.Filas = append(carreras.Filas, []string{
+			r.CircuitShortName, formatFecha(r.DateStart),
+			strconv.Itoa(r.PositionA), strconv.Itoa(r.PositionB), adelante,
+			posicionOpcional(r.QualifyingA), posicionOpcional(r.QualifyingB),
+			SaM(r.BestLapA), SaM(r.BestLapB), delta,
+			fmt.Sprintf("%.0f", r.TopSpeedA), fmt.Sprintf("%.0f", r.TopSpeedB), fmt.Sprintf("%+.0f", r.TopSpeedDelta),
+		})
+	}
+	return mostrar(h, resumen, carreras)
+}
+
+func posicionOpcional(p *int) string {
+	if p == nil {
+		return "-"
+	}
+	return strconv.Itoa(*p)
+}
+
+func tablaCarreras(carreras []client.Race, desde int) render.Tabla {
+	t := render.Tabla{Columnas: []string{"#", "ID carrera", "Tipo", "País", "Fecha", "Año", "Circuito"}}
+	for i, c := range carreras {
+		t.Filas = append(t.Filas, []string{
+			strconv.Itoa(desde + i + 1), strconv.Itoa(c.SessionKey), tipoCarrera(c.SessionName), c.CountryName, formatFecha(c.DateStart), strconv.Itoa(c.Year), c.CircuitShortName,
+		})
+	}
+	return t
+}
+
+func verCarreras(scanner *bufio.Scanner) error {
+	offset := 0
+	for {
+		carreras, err := api.Races(client.ListOptions{Limit: tamPagina, Offset: offset})
+		if err != nil {
+			return err
+		}
+		if err := mostrar(carreras, tablaCarreras(carreras.Data, offset)); err != nil {
+			return err
+		}
+		var ok bool
+		if offset, ok = navegarPaginas(scanner, carreras.Pagination); !ok {
+			return nil
+		}
+	}
+}
+
+// listarCarreras trae todas las paginas, para usar fuera del menu. tipo es
+// "sprint" o "todas"; vacio lista solo las carreras.
+func listarCarreras(tipo string) error {
+	opts := client.ListOptions{}
+	switch tipo {
+	case "":
+	case "sprint":
+		opts.Type = "sprint"
+	case "todas":
+		opts.Type = "all"
+	default:
+		return fmt.Errorf("tipo %q desconocido (sprint o todas)", tipo)
+	}
+	todas, err := api.AllRaces(opts)
+	if err != nil {
+		return err
+	}
+	return mostrar(todas, tablaCarreras(todas, 0))
+}
+
+func listarGrandesPremios(year string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil {
+		return fmt.Errorf("el año debe ser un número")
+	}
+	gps, err := api.AllMeetings(client.ListOptions{Year: num})
+	if err != nil {
+		return err
+	}
+	t := render.Tabla{
+		Titulo:   fmt.Sprintf("Grandes premios - Temporada %d", num),
+		Columnas: []string{"#", "ID gran premio", "Nombre", "País", "Circuito", "Fecha"},
+	}
+	for i, m := range gps {
+		t.Filas = append(t.Filas, []string{
+			strconv.Itoa(i + 1), strconv.Itoa(m.MeetingKey), m.MeetingName, m.CountryName, m.CircuitShortName, formatFecha(m.DateStart),
+		})
+	}
+	return mostrar(gps, t)
+}
+
+// verGranPremio muestra las sesiones del fin de semana en orden. Desde el
+// menu (scanner distinto de nil) se muestran de a una y Enter pasa a la
+// siguiente.
+func verGranPremio(id string, scanner *bufio.Scanner) error {
+	num, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return fmt.Errorf("el ID del gran premio debe ser un número")
+	}
+	gp, err := api.Meeting(num)
+	if err != nil {
+		return err
+	}
+	if len(gp.Sessions) == 0 && formato != render.JSON {
+		fmt.Printf("%s no tiene sesiones cargadas.\n", gp.MeetingName)
+		return nil
+	}
+
+	var tablas []render.Tabla
+	for _, s := range gp.Sessions {
+		t := render.Tabla{
+			Titulo:   fmt.Sprintf("%s - %s (%d) - %s", gp.MeetingName, nombreSesion(s.SessionName), s.SessionKey, formatFecha(s.DateStart)),
+			Columnas: []string{"Pos", "Piloto", "Equipo", "País"},
+		}
+		for _, e := range s.Top3 {
+			t.Filas = append(t.Filas, []string{strconv.Itoa(e.Position), e.Driver, e.TeamName, e.CountryCode})
+		}
+		if s.FastestLap != nil {
+			t.Titulo += fmt.Sprintf(" - Vuelta más rápida: %s %s", s.FastestLap.Driver, SaM(s.FastestLap.LapDuration))
+		}
+		tablas = append(tablas, t)
+	}
+	if scanner == nil || formato == render.JSON {
+		return mostrar(gp, tablas...)
+	}
+	for i, t := range tablas {
+		if err := mostrar(gp.Sessions[i], t); err != nil {
+			return err
+		}
+		if i == len(tablas)-1 {
+			return nil
+		}
+		fmt.Print("Enter para la siguiente sesión, v para volver: ")
+		scanner.Scan()
+		if strings.TrimSpace(scanner.Text()) == "v" {
+			return nil
+		}
+	}
+	return nil
+}
+
+// nombreSesion traduce el nombre de sesion de OpenF1.
+func nombreSesion(nombre string) string {
+	switch nombre {
+	case "Race":
+		return "Carrera"
+	case "Qualifying":
+		return "Clasificación"
+	case "Sprint Qualifying":
+		return "Clasificación sprint"
+	}
+	if strings.HasPrefix(nombre, "Practice ") {
+		return "Práctica " + strings.TrimPrefix(nombre, "Practice ")
+	}
+	return nombre
+}
+
+func tipoCarrera(nombre string) string {
+	if nombre == client.SessionSprint {
+		return "Sprint"
+	}
+	return "Carrera"
+}
+
+func verDetalleCarrera(id string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return fmt.Errorf("el ID de la carrera debe ser un número")
+	}
+	data, err := api.RaceDetail(num)
+	if err != nil {
+		return err
+	}
+
+	podio := render.Tabla{Titulo: "Podio - " + tipoCarrera(data.SessionName), Columnas: []string{"Posición", "Piloto", "Equipo", "País"}}
+	for _, r := range data.Podium {
+		podio.Filas = append(podio.Filas, []string{strconv.Itoa(r.Position), r.Driver, r.TeamName, r.CountryCode})
+	}
+	resultados := render.Tabla{
+		Titulo:   "Clasificación",
+		Columnas: []string{"Pos", "Nº", "Piloto", "Equipo", "Estado", "Vueltas", "Diferencia", "Puntos"},
+	}
+	for _, r := range data.Classification {
+		diferencia := ""
+		if r.GapToWinner != nil {
+			diferencia = fmt.Sprintf("+%.3f", *r.GapToWinner)
+		}
+		puntos := strconv.Itoa(r.Points)
+		if r.FastestLap {
+			puntos += " (VR)"
+		}
+		resultados.Filas = append(resultados.Filas, []string{
+			strconv.Itoa(r.Position), strconv.Itoa(r.DriverNumber), r.Driver, r.TeamName, estadoCarrera(r.Status),
+			strconv.Itoa(r.LapsCompleted), diferencia, puntos,
+		})
+	}
+	vl := data.FastestLap
+	vuelta := render.Tabla{
+		Titulo:   "Vuelta más rápida",
+		Columnas: []string{"Piloto", "Tiempo Total", "Sector 1 (s)", "Sector 2 (s)", "Sector 3 (s)"},
+		Filas: [][]string{{
+			vl.Driver, SaM(vl.LapDuration), fmt.Sprintf("%.3f", vl.Sector1), fmt.Sprintf("%.3f", vl.Sector2), fmt.Sprintf("%.3f", vl.Sector3),
+		}},
+	}
+	vi := data.IdealLap
+	ideal := render.Tabla{
+		Titulo:   fmt.Sprintf("Vuelta ideal de la carrera: %s", SaM(vi.LapDuration)),
+		Columnas: []string{"Sector", "Piloto", "Tiempo (s)"},
+	}
+	for i, s := range []client.PurpleSector{vi.Sector1, vi.Sector2, vi.Sector3} {
+		ideal.Filas = append(ideal.Filas, []string{strconv.Itoa(i + 1), s.Driver, fmt.Sprintf("%.3f", s.Duration)})
+	}
+	idealPilotos := render.Tabla{
+		Titulo:   "Vuelta ideal por piloto",
+		Columnas: []string{"#", "Piloto", "Sector 1 (s)", "Sector 2 (s)", "Sector 3 (s)", "Vuelta ideal", "Mejor vuelta", "Diferencia (s)"},
+	}
+	for i, p := range vi.Drivers {
+		mejor, diferencia := "-", "-"
+		if p.BestLap > 0 {
+			mejor, diferencia = SaM(p.BestLap), fmt.Sprintf("%.3f", p.BestLap-p.IdealLap)
+		}
+		idealPilotos.Filas = append(idealPilotos.Filas, []string{
+			strconv.Itoa(i + 1), p.Driver,
+			fmt.Sprintf("%.3f", p.Sector1), fmt.Sprintf("%.3f", p.Sector2), fmt.Sprintf("%.3f", p.Sector3),
+			SaM(p.IdealLap), mejor, diferencia,
+		})
+	}
+	vs := data.MaxSpeed
+	velocidad := render.Tabla{
+		Titulo:   "Velocidad máxima alcanzada",
+		Columnas: []string{"Piloto", "Velocidad (km/h)"},
+		Filas:    [][]string{{vs.Driver, fmt.Sprintf("%.0f", vs.SpeedKmh)}},
+	}
+	estrategia := render.Tabla{Titulo: "Estrategia", Columnas: []string{"Piloto", "Stints (compuesto vueltas, edad inicial)", "Paradas (vuelta, tiempo en boxes)"}}
+	for _, e := range data.Strategy {
+		var stints, paradas []string
+		for _, st := range e.Stints {
+			stints = append(stints, fmt.Sprintf("%s %d-%d (%d)", st.Compound, st.LapStart, st.LapEnd, st.TyreAgeAtStart))
+		}
+		for _, ps := range e.PitStops {
+			duracion := "-"
+			if ps.PitDuration != nil {
+				duracion = fmt.Sprintf("%.1fs", *ps.PitDuration)
+			}
+			paradas = append(paradas, fmt.Sprintf("V%d %s", ps.LapNumber, duracion))
+		}
+		estrategia.Filas = append(estrategia.Filas, []string{e.Driver, strings.Join(stints, ", "), strings.Join(paradas, ", ")})
+	}
+	tablas := []render.Tabla{podio, resultados, vuelta, ideal, idealPilotos, velocidad}
+	if len(estrategia.Filas) > 0 {
+		tablas = append(tablas, estrategia)
+	}
+	if w := data.Weather; w != nil {
+		tablas = append(tablas, render.Tabla{
+			Titulo:   "Clima",
+			Columnas: []string{"Aire (°C)", "Pista (°C)", "Humedad (%)", "Lluvia"},
+			Filas: [][]string{{
+				fmt.Sprintf("%.1f - %.1f", w.AirTemperatureMin, w.AirTemperatureMax),
+				fmt.Sprintf("%.1f - %.1f", w.TrackTemperatureMin, w.TrackTemperatureMax),
+				fmt.Sprintf("%.0f", w.HumidityAvg), boolToStr(w.Rainfall),
+			}},
+		})
+	}
+	return mostrar(data, tablas...)
+}
+
+func verRitmo(id string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return fmt.Errorf("el ID de la carrera debe ser un número")
+	}
+	data, err := api.RacePace(num)
+	if err != nil {
+		return err
+	}
+	if len(data.Drivers) == 0 && formato != render.JSON {
+		fmt.Println("No hay vueltas registradas para esta carrera.")
+		return nil
+	}
+
+	opcional := func(v *float64, f string) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf(f, *v)
+	}
+	ritmo := render.Tabla{
+		Titulo:   fmt.Sprintf("Ritmo de carrera %d (vueltas limpias)", num),
+		Columnas: []string{"#", "Piloto", "Equipo", "Pos Final", "Vueltas", "Neutralizadas", "Mediana", "Media", "Desv (s)", "Degradación (s/vuelta)", "% vs ganador", "Stints"},
+	}
+	for i, d := range data.Drivers {
+		var stints []string
+		for _, s := range d.Stints {
+			stints = append(stints, fmt.Sprintf("%d-%d", s.StartLap, s.EndLap))
+		}
+		ritmo.Filas = append(ritmo.Filas, []string{
+			strconv.Itoa(i + 1), d.Driver, d.TeamName, posicionOpcional(d.Position), strconv.Itoa(d.CleanLaps), strconv.Itoa(d.NeutralisedLaps),
+			SaM(d.MedianLap), SaM(d.MeanLap), fmt.Sprintf("%.3f", d.StdDev),
+			opcional(d.DegradationPerLap, "%+.3f"), opcional(d.GapToWinnerPct, "%+.2f"), strings.Join(stints, " "),
+		})
+	}
+	return mostrar(data, ritmo)
+}
+
+func verPractica(id string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return fmt.Errorf("el ID de la sesión debe ser un número")
+	}
+	data, err := api.Practice(num)
+	if err != nil {
+		return err
+	}
+	if len(data.Drivers) == 0 && formato != render.JSON {
+		fmt.Println("No hay vueltas registradas para esta práctica.")
+		return nil
+	}
+
+	opcional := func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return SaM(*v)
+	}
+	carrera := "Carrera"
+	if data.RaceSessionKey != nil {
+		carrera = fmt.Sprintf("Carrera %d", *data.RaceSessionKey)
+	}
+	resumen := render.Tabla{
+		Titulo:   fmt.Sprintf("%s %d", nombreSesion(data.SessionName), num),
+		Columnas: []string{"#", "Piloto", "Equipo", "Vueltas", "Mejor vuelta", "Ritmo limpio", "Tanda corta", "Tanda larga", "Vueltas tanda larga", carrera},
+	}
+	largas := render.Tabla{
+		Titulo:   "Tandas largas",
+		Columnas: []string{"Piloto", "Vueltas", "Desde", "Hasta", "Compuesto", "Mediana", "Mejor", "Degradación (s/vuelta)"},
+	}
+	for _, d := range data.Drivers {
+		resultado := posicionOpcional(d.RacePosition)
+		if d.RaceStatus != "" && d.RaceStatus != client.StatusFinished {
+			resultado += " (" + estadoCarrera(d.RaceStatus) + ")"
+		}
+		resumen.Filas = append(resumen.Filas, []string{
+			strconv.Itoa(d.Position), d.Driver, d.TeamName, strconv.Itoa(d.TotalLaps), SaM(d.BestLap), SaM(d.CleanLapPace),
+			opcional(d.ShortRunPace), opcional(d.LongRunPace), strconv.Itoa(d.LongRunLaps), resultado,
+		})
+		for _, r := range d.Runs {
+			if !r.LongRun {
+				continue
+			}
+			degradacion := "-"
+			if r.DegradationPerLap != nil {
+				degradacion = fmt.Sprintf("%+.3f", *r.DegradationPerLap)
+			}
+			largas.Filas = append(largas.Filas, []string{
+				d.Driver, strconv.Itoa(r.Laps), strconv.Itoa(r.StartLap), strconv.Itoa(r.EndLap), r.Compound,
+				SaM(r.MedianLap), SaM(r.BestLap), degradacion,
+			})
+		}
+	}
+	if len(largas.Filas) == 0 {
+		return mostrar(data, resumen)
+	}
+	return mostrar(data, resumen, largas)
+}
+
+// verDiferencias sin pilotos muestra la diferencia final de cada uno; con
+// pilotos muestra la diferencia al lider vuelta a vuelta, una columna por piloto.
+func verDiferencias(id string, numeros []string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return fmt.Errorf("el ID de la carrera debe ser un número")
+	}
+	var pilotos []int
+	for _, n := range numeros {
+		p, err := strconv.Atoi(n)
+		if err != nil {
+			return fmt.Errorf("los números de piloto deben ser números")
+		}
+		pilotos = append(pilotos, p)
+	}
+	data, err := api.RaceGaps(num, pilotos)
+	if err != nil {
+		return err
+	}
+
+	if len(pilotos) == 0 {
+		finales := render.Tabla{
+			Titulo:   fmt.Sprintf("Diferencias al final de la carrera %d", num),
+			Columnas: []string{"Piloto", "Vuelta", "Al líder", "Al de adelante"},
+		}
+		for _, d := range data.Drivers {
+			if len(d.Points) == 0 {
+				continue
+			}
+			u := d.Points[len(d.Points)-1]
+			finales.Filas = append(finales.Filas, []string{d.Driver, strconv.Itoa(u.LapNumber), formatGap(u.GapToLeader, u.LapsBehind), formatGap(u.Interval, u.LapsBehind)})
+		}
+		if len(finales.Filas) == 0 && formato != render.JSON {
+			fmt.Println("No hay datos de intervalos para esta carrera.")
+			return nil
+		}
+		return mostrar(data, finales)
+	}
+
+	porVuelta := render.Tabla{Titulo: fmt.Sprintf("Diferencia al líder en la carrera %d", num), Columnas: []string{"Vuelta"}}
+	ultima := 0
+	gaps := make([]map[int]string, len(data.Drivers))
+	for i, d := range data.Drivers {
+		porVuelta.Columnas = append(porVuelta.Columnas, d.Driver)
+		gaps[i] = map[int]string{}
+		for _, p := range d.Points {
+			gaps[i][p.LapNumber] = formatGap(p.GapToLeader, p.LapsBehind)
+			if p.LapNumber > ultima {
+				ultima = p.LapNumber
+			}
+		}
+	}
+	for v := 1; v <= ultima; v++ {
+		fila := []string{strconv.Itoa(v)}
+		for i := range data.Drivers {
+			g, ok := gaps[i][v]
+			if !ok {
+				g = "-"
+			}
+			fila = append(fila, g)
+		}
+		porVuelta.Filas = append(porVuelta.Filas, fila)
+	}
+	return mostrar(data, porVuelta)
+}
+
+// estadoCarrera traduce el estado de la clasificacion para mostrarlo.
+func estadoCarrera(estado string) string {
+	switch estado {
+	case client.StatusFinished:
+		return "Terminó"
+	case client.StatusDNF:
+		return "Abandonó"
+	case client.StatusDSQ:
+		return "Descalificado"
+	case client.StatusDNS:
+		return "No largó"
+	}
+	var vueltas int
+	if _, err := fmt.Sscanf(estado, "+%d", &vueltas); err == nil {
+		if vueltas == 1 {
+			return "+1 vuelta"
+		}
+		return fmt.Sprintf("+%d vueltas", vueltas)
+	}
+	return estado
+}
+
+func formatGap(segundos *float64, vueltas int) string {
+	switch {
+	case segundos != nil:
+		return fmt.Sprintf("+%.3f", *segundos)
+	case vueltas == 1:
+		return "+1 vuelta"
+	case vueltas > 1:
+		return fmt.Sprintf("+%d vueltas", vueltas)
+	}
+	return "-"
+}
+
+func verTelemetria(idCarrera, idPiloto, idVuelta, cantidad string) error {
+	var numeros [4]int
+	for i, s := range []string{idCarrera, idPiloto, idVuelta, cantidad} {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("el ID de la carrera, el piloto, la vuelta y los puntos deben ser números")
+		}
+		numeros[i] = n
+	}
+	data, err := api.LapTelemetry(numeros[0], numeros[1], numeros[2], numeros[3])
+	if err != nil {
+		return err
+	}
+	t := render.Tabla{
+		Titulo: fmt.Sprintf("Telemetría del piloto %d, vuelta %d (%d muestras, máxima %.0f km/h)",
+			data.DriverNumber, data.LapNumber, data.Samples, data.MaxSpeed),
+		Columnas: []string{"Tiempo (s)", "Distancia (m)", "Velocidad (km/h)", "Acelerador (%)", "Freno", "Marcha", "RPM", "DRS"},
+	}
+	for _, p := range data.Points {
+		t.Filas = append(t.Filas, []string{
+			fmt.Sprintf("%.2f", p.Time), fmt.Sprintf("%.0f", p.Distance), fmt.Sprintf("%.0f", p.Speed), fmt.Sprintf("%.0f", p.Throttle),
+			strconv.Itoa(p.Brake), strconv.Itoa(p.Gear), strconv.Itoa(p.RPM), strconv.Itoa(p.DRS),
+		})
+	}
+	return mostrar(data, t)
+}
+
+// verComparacionVueltas muestra las dos vueltas superpuestas. Con -o csv la
+// salida se puede graficar directamente.
+func verComparacionVueltas(idCarrera, a, b, cantidad string) error {
+	carrera, errC := strconv.Atoi(strings.TrimSpace(idCarrera))
+	puntos, errP := strconv.Atoi(strings.TrimSpace(cantidad))
+	if errC != nil || errP != nil {
+		return fmt.Errorf("el ID de la carrera y los puntos deben ser números")
+	}
+	var pilotos, vueltas [2]int
+	for i, v := range []string{a, b} {
+		if _, err := fmt.Sscanf(v, "%d:%d", &pilotos[i], &vueltas[i]); err != nil {
+			return fmt.Errorf("%q debe tener la forma número:vuelta, por ejemplo 1:45", v)
+		}
+	}
+	data, err := api.CompareLaps(carrera, pilotos[0], vueltas[0], pilotos[1], vueltas[1], puntos)
+	if err != nil {
+		return err
+	}
+	t := render.Tabla{
+		Titulo: fmt.Sprintf("A: %s vuelta %d (%s) - B: %s vuelta %d (%s)",
+			data.A.Driver, data.A.LapNumber, SaM(data.A.LapDuration), data.B.Driver, data.B.LapNumber, SaM(data.B.LapDuration)),
+		Columnas: []string{"Distancia (m)", "Velocidad A", "Velocidad B", "Acelerador A", "Acelerador B", "Freno A", "Freno B", "Δ B-A (s)"},
+	}
+	for _, p := range data.Points {
+		t.Filas = append(t.Filas, []string{
+			fmt.Sprintf("%.0f", p.Distance), fmt.Sprintf("%.0f", p.SpeedA), fmt.Sprintf("%.0f", p.SpeedB),
+			fmt.Sprintf("%.0f", p.ThrottleA), fmt.Sprintf("%.0f", p.ThrottleB),
+			strconv.Itoa(p.BrakeA), strconv.Itoa(p.BrakeB), fmt.Sprintf("%+.3f", p.Delta),
+		})
+	}
+	return mostrar(data, t)
+}
+
+func verVueltas(idCarrera, idPiloto string, soloValidas bool) error {
+	carrera, errC := strconv.Atoi(strings.TrimSpace(idCarrera))
+	piloto, errP := strconv.Atoi(strings.TrimSpace(idPiloto))
+	if errC != nil || errP != nil {
+		return fmt.Errorf("el ID de la carrera y el número del piloto deben ser números")
+	}
+	data, err := api.DriverLaps(carrera, piloto, soloValidas)
+	if err != nil {
+		return err
+	}
+	if len(data.Laps) == 0 && formato != render.JSON {
+		fmt.Println("No hay vueltas registradas para este piloto en esta carrera.")
+		return nil
+	}
+
+	delta := func(d *float64) string {
+		if d == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%+.3f", *d)
+	}
+	vueltas := render.Tabla{
+		Titulo:   fmt.Sprintf("Vueltas del piloto %d en la carrera %d", piloto, carrera),
+		Columnas: []string{"Vuelta", "Tiempo", "Sector 1 (s)", "Sector 2 (s)", "Sector 3 (s)", "Trampa (km/h)", "Válida", "Pista", "Δ mejor propia", "Δ mejor carrera"},
+	}
+	for _, l := range data.Laps {
+		tiempo := "-"
+		if l.LapDuration > 0 {
+			tiempo = SaM(l.LapDuration)
+		}
+		vueltas.Filas = append(vueltas.Filas, []string{
+			strconv.Itoa(l.LapNumber), tiempo,
+			fmt.Sprintf("%.3f", l.Sector1), fmt.Sprintf("%.3f", l.Sector2), fmt.Sprintf("%.3f", l.Sector3),
+			fmt.Sprintf("%.0f", l.StSpeed), boolToStr(l.Valid), l.TrackStatus,
+			delta(l.DeltaToPersonalBest), delta(l.DeltaToSessionBest),
+		})
+	}
+	return mostrar(data, vueltas)
+}
+
+func verResumenTemporada(year string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil {
+		return fmt.Errorf("el año debe ser un número")
+	}
+	// Se piden los empatados para no dejar afuera a nadie con el mismo valor.
+	resumen, err := api.SeasonSummary(num, true)
+	if err != nil {
+		return err
+	}
+
+	return mostrar(resumen,
+		Resumen("Victorias", resumen.Season, resumen.Winners),
+		Resumen("Vueltas Rapidas", resumen.Season, resumen.FastestLaps),
+		Resumen("Pole Positions", resumen.Season, resumen.PolePositions),
+		Resumen("Puntos", resumen.Season, resumen.Points),
+		Resumen("Victorias en sprint", resumen.Season, resumen.SprintWinners),
+		Resumen("Puntos en sprint", resumen.Season, resumen.SprintPoints))
+}
+
+func verLideres(id string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return fmt.Errorf("el ID de la carrera debe ser un número")
+	}
+	lideres, err := api.RaceLeaders(num)
+	if err != nil {
+		return err
+	}
+	if len(lideres.Laps) == 0 && formato != render.JSON {
+		fmt.Println("No hay historial de posiciones para esta carrera.")
+		return nil
+	}
+
+	pilotos := render.Tabla{
+		Titulo:   fmt.Sprintf("Vueltas lideradas en la carrera %d", num),
+		Columnas: []string{"Número", "Piloto", "Equipo", "Vueltas lideradas"},
+	}
+	for _, p := range lideres.Drivers {
+		pilotos.Filas = append(pilotos.Filas, []string{strconv.Itoa(p.DriverNumber), p.Driver, p.TeamName, strconv.Itoa(p.LapsLed)})
+	}
+	// Las vueltas seguidas del mismo lider van en una sola fila.
+	tramos := render.Tabla{Titulo: "Líder vuelta a vuelta", Columnas: []string{"Vueltas", "Líder"}}
+	for i := 0; i < len(lideres.Laps); {
+		j := i
+		for j+1 < len(lideres.Laps) && lideres.Laps[j+1].DriverNumber == lideres.Laps[i].DriverNumber {
+			j++
+		}
+		vueltas := strconv.Itoa(lideres.Laps[i].LapNumber)
+		if j > i {
+			vueltas += "-" + strconv.Itoa(lideres.Laps[j].LapNumber)
+		}
+		tramos.Filas = append(tramos.Filas, []string{vueltas, lideres.Laps[i].Driver})
+		i = j + 1
+	}
+	return mostrar(lideres, pilotos, tramos)
+}
+
+func verFiabilidad(year string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil {
+		return fmt.Errorf("el año debe ser un número")
+	}
+	reporte, err := api.TeamReliability(num)
+	if err != nil {
+		return err
+	}
+	if len(reporte.Teams) == 0 && formato != render.JSON {
+		fmt.Println("No hay resultados guardados para esa temporada.")
+		return nil
+	}
+
+	t := render.Tabla{
+		Titulo:   fmt.Sprintf("Fiabilidad de equipos - Temporada %d", reporte.Year),
+		Columnas: []string{"#", "Equipo", "Largadas", "Terminadas", "Abandonos", "Descalificaciones", "Fiabilidad"},
+	}
+	for i, e := range reporte.Teams {
+		t.Filas = append(t.Filas, []string{
+			strconv.Itoa(i + 1),
+			e.TeamName,
+			strconv.Itoa(e.Starts),
+			strconv.Itoa(e.Finishes),
+			strconv.Itoa(e.DNFs),
+			strconv.Itoa(e.DSQs),
+			fmt.Sprintf("%.0f%%", e.ReliabilityRate*100),
+		})
+	}
+	return mostrar(reporte, t)
+}
+
+func verCompaneros(equipo, year string) error {
+	num, err := strconv.Atoi(strings.TrimSpace(year))
+	if err != nil {
+		return fmt.Errorf("el año debe ser un número")
+	}
+	equipo = strings.TrimSpace(equipo)
+	if equipo == "" {
+		return fmt.Errorf("falta el equipo")
+	}
+	t, err := api.Teammates(equipo, num)
+	if err != nil {
+		return err
+	}
+
+	puntos := render.Tabla{
+		Titulo:   fmt.Sprintf("%s - Temporada %d - %d puntos", t.TeamName, t.Year, t.Points),
+		Columnas: []string{"Número", "Piloto", "Carreras", "Puntos", "Parte del equipo"},
+	}
+	for _, p := range t.Drivers {
+		puntos.Filas = append(puntos.Filas, []string{
+			strconv.Itoa(p.DriverNumber), p.Driver, strconv.Itoa(p.Races), strconv.Itoa(p.Points), fmt.Sprintf("%.0f%%", p.Share*100),
+		})
+	}
+
+	delta := func(d *float64) string {
+		if d == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%+.3f", *d)
+	}
+	parejas := render.Tabla{
+		Titulo: "Compañeros (diferencias de A contra B, negativo si A fue más rápido)",
+		Columnas: []string{"Piloto A", "Piloto B", "Carreras juntos", "Carreras A-B", "Clasificaciones A-B",
+			"Δ clasificación (s)", "Δ mejor vuelta carrera (s)", "Puntos A", "Puntos B"},
+	}
+	for _, p := range t.Pairs {
+		parejas.Filas = append(parejas.Filas, []string{
+			p.DriverA.Driver, p.DriverB.Driver, strconv.Itoa(p.RacesTogether),
+			fmt.Sprintf("%d-%d", p.RaceHeadToHead.A, p.RaceHeadToHead.B),
+			fmt.Sprintf("%d-%d", p.QualifyingHeadToHead.A, p.QualifyingHeadToHead.B),
+			delta(p.AvgQualifyingGap), delta(p.AvgRaceBestLapGap),
+			strconv.Itoa(p.PointsA), strconv.Itoa(p.PointsB),
+		})
+	}
+	return mostrar(t, puntos, parejas)
+}
+
+func listarMetricas() error {
+	lista, err := api.Metrics()
+	if err != nil {
+		return err
+	}
+	t := render.Tabla{Titulo: "Estadísticas disponibles", Columnas: []string{"Métrica", "Descripción", "Unidad"}}
+	for _, m := range lista.Metrics {
+		t.Filas = append(t.Filas, []string{m.Name, m.Description, m.Unit})
+	}
+	return mostrar(lista, t)
+}
+
+// verEstadisticas muestra el ranking de una metrica. alcance es un año o
+// "historico" para todas las temporadas cargadas.
+func verEstadisticas(metrica, alcance, cantidad string) error {
+	limite, err := strconv.Atoi(strings.TrimSpace(cantidad))
+	if err != nil {
+		return fmt.Errorf("la cantidad debe ser un número")
+	}
+	scope, year := client.ScopeSeason, 0
+	if strings.EqualFold(strings.TrimSpace(alcance), "historico") {
+		scope = client.ScopeCareer
+	} else if year, err = strconv.Atoi(strings.TrimSpace(alcance)); err != nil {
+		return fmt.Errorf("el año debe ser un número o \"historico\"")
+	}
+	ranking, err := api.Leaderboard(strings.TrimSpace(metrica), scope, year, limite, true)
+	if err != nil {
+		return err
+	}
+
+	titulo := fmt.Sprintf("%s - Histórico", ranking.Metric.Description)
+	if ranking.Year != nil {
+		titulo = fmt.Sprintf("%s - Temporada %d", ranking.Metric.Description, *ranking.Year)
+	}
+	t := render.Tabla{Titulo: titulo, Columnas: []string{"Posicion", "Piloto", "Equipo", "Pais", ranking.Metric.Unit}}
+	for _, e := range ranking.Entries {
+		t.Filas = append(t.Filas, []string{
+			strconv.Itoa(e.Position), e.Driver, e.TeamName, e.CountryCode, strconv.FormatFloat(e.Value, 'f', -1, 64),
+		})
+	}
+	return mostrar(ranking, t)
+}
+
+func Resumen(titulo string, temporada int, lista []client.SeasonStat) render.Tabla {
+	t := render.Tabla{
+		Titulo:   fmt.Sprintf("Top 3 Pilotos con mas %s - Temporada %d", titulo, temporada),
+		Columnas: []string{"Posicion", "Piloto", "Equipo", "Pais", titulo},
+	}
+	for _, fila := range lista {
+		t.Filas = append(t.Filas, []string{strconv.Itoa(fila.Position), fila.Driver, fila.TeamName, fila.CountryCode, strconv.Itoa(fila.Value)})
+	}
+	return t
+}
+
+// navegarPaginas muestra la posicion actual y pregunta por la siguiente
+// pagina. Devuelve el nuevo offset, o false si el usuario quiere volver.
+func navegarPaginas(scanner *bufio.Scanner, pag client.Pagination) (int, bool) {
+	hasta := pag.Offset + pag.Limit
+	if hasta > pag.Total {
+		hasta = pag.Total
+	}
+	fmt.Printf("Mostrando %d-%d de %d\n", pag.Offset+1, hasta, pag.Total)
+	for {
+		fmt.Print("[s] siguiente, [a] anterior, Enter para volver: ")
+		scanner.Scan()
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "s":
+			if pag.NextOffset != nil {
+				return *pag.NextOffset, true
+			}
+			fmt.Println("Ya está en la última página.")
+		case "a":
+			if pag.Offset > 0 {
+				anterior := pag.Offset - pag.Limit
+				if anterior < 0 {
+					anterior = 0
+				}
+				return anterior, true
+			}
+			fmt.Println("Ya está en la primera página.")
+		case "":
+			return 0, false
+		default:
+			fmt.Println("Opción inválida.")
+		}
+	}
+}
+
+func SaM(segundos float64) string {
+	min := int(segundos) / 60
+	sec := segundos - float64(min*60)
+	return fmt.Sprintf("%d:%06.3f", min, sec)
+}
+
+func boolToStr(b bool) string {
+	if b {
+		return "Sí"
+	}
+	return "No"
+}
+
+func formatFecha(s string) string {
+	// Quita la T y lo que venga después
+	if i := strings.Index(s, "T"); i != -1 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,4181 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/mattn/go-sqlite3"
+
+	"tarea1sd/client"
+	"tarea1sd/openapi"
+)
+
+var db *sql.DB
+
+type Session struct {
+	SessionKey        int    `json:"session_key"`
+	SessionName       string `json:"session_name"`
+	SessionType       string `json:"session_type"`
+	Location          string `json:"location"`
+	CountryName       string `json:"country_name"`
+	Year              int    `json:"year"`
+	CircuitShortName  string `json:"circuit_short_name"`
+	DateStart         string `json:"date_start"`
+	MeetingKey        int    `json:"meeting_key"`
+}
+
+type Lap struct {
+	DriverNumber     int     `json:"driver_number"`
+	SessionKey       int     `json:"session_key"`
+	LapNumber        int     `json:"lap_number"`
+	LapDuration      float64 `json:"lap_duration"`
+	DurationSector1  float64 `json:"duration_sector_1"`
+	DurationSector2  float64 `json:"duration_sector_2"`
+	DurationSector3  float64 `json:"duration_sector_3"`
+	StSpeed          float64 `json:"st_speed"`
+	DateStart        string  `json:"date_start"`
+}
+
+type Position struct {
+	DriverNumber int    `json:"driver_number"`
+	SessionKey   int    `json:"session_key"`
+	Position     int    `json:"position"`
+	Date         string `json:"date"`
+}
+
+// Tipos de /api/v1. Conservan las claves originales para no romper a los
+// clientes que ya los consumen; los de /api/v2 estan en el paquete client.
+
+type DriverDetailV1 struct {
+	DriverID           string                    `json:"driver_id"`
+	PerformanceSummary client.PerformanceSummary `json:"performance_summary"`
+	RaceResults        []client.RaceResult       `json:"race_results"`
+}
+
+type ResultadoV1 struct {
+	Position interface{} `json:"position"` // numero, o "Ultimo" para el ultimo clasificado
+	Driver   string      `json:"driver"`
+	Team     string      `json:"team"`
+	Country  string      `json:"country"`
+}
+
+type FastestLapV1 struct {
+	Driver    string  `json:"driver"`
+	TotalTime float64 `json:"total_time"`
+	Sector1   float64 `json:"sector_1"`
+	Sector2   float64 `json:"sector_2"`
+	Sector3   float64 `json:"sector_3"`
+}
+
+// Results mantiene el formato original (podio y "Ultimo") para los clientes
+// de v1; la clasificacion completa esta en Classification.
+type RaceDetailV1 struct {
+	RaceID         string                       `json:"race_id"`
+	SessionName    string                       `json:"session_name"`
+	Results        []ResultadoV1                `json:"results"`
+	Podium         []client.ClassificationEntry `json:"podium"`
+	Classification []client.ResultEntry         `json:"classification"`
+	FastestLap     FastestLapV1                 `json:"fastest_lap"`
+	IdealLap       client.IdealLap              `json:"ideal_lap"`
+	MaxSpeed       client.MaxSpeed              `json:"max_speed"`
+	Strategy       []client.DriverStrategy      `json:"strategy"`
+	Weather        *client.WeatherSummary       `json:"weather"`
+}
+
+type StatV1 struct {
+	Position    int    `json:"position"`
+	Driver      string `json:"driver"`
+	Value       int    `json:"Value"`
+	TeamName    string `json:"team_name"`
+	CountryCode string `json:"country_code"`
+}
+
+type SeasonSummaryV1 struct {
+	Season            int      `json:"season"`
+	Top3Winners       []StatV1 `json:"top_3_winners"`
+	Top3FastestLaps   []StatV1 `json:"top_3_fastest_laps"`
+	Top3PolePositions []StatV1 `json:"top_3_pole_positions"`
+}
+
+// ruta une un handler con la descripcion que se publica en /api/openapi.json.
+type ruta struct {
+	openapi.Operacion
+	handler gin.HandlerFunc
+}
+
+var paramsPaginacion = []openapi.Parametro{
+	{Nombre: "limit", En: "query", Tipo: "integer", Descripcion: "Filas por página (1-100, por defecto 20)"},
+	{Nombre: "offset", En: "query", Tipo: "integer", Descripcion: "Filas a saltar"},
+	{Nombre: "sort", En: "query", Tipo: "string", Descripcion: "Campos separados por coma; un \"-\" al inicio ordena descendente"},
+}
+
+var paramsPilotos = append([]openapi.Parametro{
+	{Nombre: "team", En: "query", Tipo: "string", Descripcion: "Filtra por nombre de equipo"},
+	{Nombre: "country", En: "query", Tipo: "string", Descripcion: "Filtra por código de país"},
+}, paramsPaginacion...)
+
+var paramsCarreras = append([]openapi.Parametro{
+	{Nombre: "country", En: "query", Tipo: "string", Descripcion: "Filtra por país"},
+	{Nombre: "year", En: "query", Tipo: "integer", Descripcion: "Filtra por año"},
+	{Nombre: "circuit", En: "query", Tipo: "string", Descripcion: "Filtra por circuito"},
+	{Nombre: "type", En: "query", Tipo: "string", Descripcion: "race (por defecto), sprint o all"},
+}, paramsPaginacion...)
+
+var paramsGrandesPremios = append([]openapi.Parametro{
+	{Nombre: "country", En: "query", Tipo: "string", Descripcion: "Filtra por país"},
+	{Nombre: "year", En: "query", Tipo: "integer", Descripcion: "Filtra por año"},
+}, paramsPaginacion...)
+
+var paramsTemporadaPiloto = []openapi.Parametro{
+	{Nombre: "year", En: "query", Tipo: "integer", Descripcion: "Temporada, por defecto 2024"},
+}
+
+var paramsComparacion = []openapi.Parametro{
+	{Nombre: "a", En: "query", Tipo: "integer", Descripcion: "Número del primer piloto", Requerido: true},
+	{Nombre: "b", En: "query", Tipo: "integer", Descripcion: "Número del segundo piloto", Requerido: true},
+}
+
+var paramsVueltas = []openapi.Parametro{
+	{Nombre: "session_key", En: "path", Tipo: "integer"},
+	{Nombre: "driver_number", En: "path", Tipo: "integer"},
+	{Nombre: "valid_only", En: "query", Tipo: "boolean", Descripcion: "Omite las vueltas sin tiempo o sin alguno de los sectores"},
+}
+
+var paramsDiferencias = []openapi.Parametro{
+	{Nombre: "drivers", En: "query", Tipo: "string", Descripcion: "Números de piloto separados por coma; por defecto todos"},
+}
+
+var paramsTelemetria = []openapi.Parametro{
+	{Nombre: "session_key", En: "path", Tipo: "integer"},
+	{Nombre: "driver_number", En: "path", Tipo: "integer"},
+	{Nombre: "lap_number", En: "path", Tipo: "integer"},
+	{Nombre: "points", En: "query", Tipo: "integer", Descripcion: "Cantidad máxima de puntos (2 a 2000, por defecto 200)"},
+}
+
+var paramsComparacionVueltas = []openapi.Parametro{
+	{Nombre: "session_key", En: "path", Tipo: "integer"},
+	{Nombre: "a", En: "query", Tipo: "string", Descripcion: "Piloto y vuelta de referencia, por ejemplo 1:45", Requerido: true},
+	{Nombre: "b", En: "query", Tipo: "string", Descripcion: "Piloto y vuelta a comparar, por ejemplo 16:44", Requerido: true},
+	{Nombre: "points", En: "query", Tipo: "integer", Descripcion: "Cantidad de puntos (2 a 2000, por defecto 200)"},
+}
+
+var paramsFiabilidad = []openapi.Parametro{
+	{Nombre: "year", En: "query", Tipo: "integer", Descripcion: "Temporada, por defecto 2024"},
+}
+
+var paramsEstadisticas = []openapi.Parametro{
+	{Nombre: "metric", En: "query", Tipo: "string", Descripcion: "Estadística (ver /estadisticas/metricas), por defecto wins"},
+	{Nombre: "scope", En: "query", Tipo: "string", Descripcion: "season (por defecto) o career"},
+	{Nombre: "year", En: "query", Tipo: "integer", Descripcion: "Temporada si scope es season, por defecto 2024"},
+	{Nombre: "limit", En: "query", Tipo: "integer", Descripcion: "Cantidad de pilotos (1-100, por defecto 10)"},
+	{Nombre: "include_ties", En: "query", Tipo: "boolean", Descripcion: "Incluye a todos los empatados en el último puesto aunque se pase de limit"},
+}
+
+var paramsResumen = []openapi.Parametro{
+	{Nombre: "include_ties", En: "query", Tipo: "boolean", Descripcion: "Incluye a todos los empatados en el tercer puesto"},
+}
+
+// El equipo va en el path como nombre o con guiones, por ejemplo
+// red-bull-racing.
+var paramsCompaneros = []openapi.Parametro{
+	{Nombre: "year", En: "query", Tipo: "integer", Descripcion: "Temporada, por defecto 2024"},
+}
+
+var rutasV1 = []ruta{
+	{openapi.Operacion{Metodo: "GET", Path: "/corredor", Resumen: "Lista de pilotos", Parametros: paramsPilotos, Respuesta: client.DriverList{}}, getDrivers},
+	{openapi.Operacion{Metodo: "GET", Path: "/corredor/detalle/:id", Resumen: "Resultados de un piloto", Respuesta: DriverDetailV1{}}, getDriverDetail},
+	{openapi.Operacion{Metodo: "GET", Path: "/corredor/detalle/:id/temporada", Resumen: "Temporada de un piloto fecha por fecha", Parametros: paramsTemporadaPiloto, Respuesta: client.DriverSeason{}}, getTemporadaPiloto},
+	{openapi.Operacion{Metodo: "GET", Path: "/corredor/comparar", Resumen: "Comparación entre dos pilotos", Parametros: paramsComparacion, Respuesta: client.HeadToHead{}}, getComparacion},
+	{openapi.Operacion{Metodo: "GET", Path: "/carrera", Resumen: "Lista de carreras", Parametros: paramsCarreras, Respuesta: client.RaceList{}}, getCarreras},
+	{openapi.Operacion{Metodo: "GET", Path: "/carrera/detalle/:id", Resumen: "Podio, vuelta rápida y velocidad máxima de una carrera", Respuesta: RaceDetailV1{}}, getCarreraDetail},
+	{openapi.Operacion{Metodo: "GET", Path: "/gp", Resumen: "Lista de grandes premios", Parametros: paramsGrandesPremios, Respuesta: client.MeetingList{}}, getGrandesPremios},
+	{openapi.Operacion{Metodo: "GET", Path: "/practica/detalle/:id", Resumen: "Tandas cortas y largas de una práctica libre", Parametros: []openapi.Parametro{{Nombre: "id", En: "path", Tipo: "integer"}}, Respuesta: client.PracticeAnalysis{}}, getPractica},
+	{openapi.Operacion{Metodo: "GET", Path: "/gp/:meeting_key", Resumen: "Fin de semana completo de un gran premio", Parametros: []openapi.Parametro{{Nombre: "meeting_key", En: "path", Tipo: "integer"}}, Respuesta: client.MeetingDetail{}}, getGranPremio},
+	{openapi.Operacion{Metodo: "GET", Path: "/carrera/detalle/:id/ritmo", Resumen: "Ritmo de carrera y consistencia de cada piloto", Parametros: []openapi.Parametro{{Nombre: "id", En: "path", Tipo: "integer"}}, Respuesta: client.RacePace{}}, getRitmo},
+	{openapi.Operacion{Metodo: "GET", Path: "/carrera/detalle/:id/lideres", Resumen: "Líder de cada vuelta y vueltas lideradas por piloto", Parametros: []openapi.Parametro{{Nombre: "id", En: "path", Tipo: "integer"}}, Respuesta: client.RaceLeaders{}}, getLideres},
+	{openapi.Operacion{Metodo: "GET", Path: "/carrera/detalle/:id/diferencias", Resumen: "Diferencia al líder y al auto de adelante vuelta a vuelta", Parametros: paramsDiferencias, Respuesta: client.RaceGaps{}}, getDiferencias},
+	{openapi.Operacion{Metodo: "GET", Path: "/carrera/:session_key/corredor/:driver_number/vueltas", Resumen: "Vueltas de un piloto en una carrera", Parametros: paramsVueltas, Respuesta: client.DriverLaps{}}, getVueltas},
+	{openapi.Operacion{Metodo: "GET", Path: "/carrera/:session_key/corredor/:driver_number/vueltas/:lap_number/telemetria", Resumen: "Telemetría de una vuelta", Parametros: paramsTelemetria, Respuesta: client.LapTelemetry{}}, getTelemetria},
+	{openapi.Operacion{Metodo: "GET", Path: "/carrera/:session_key/comparar-vuelta", Resumen: "Comparación de dos vueltas alineadas por distancia", Parametros: paramsComparacionVueltas, Respuesta: client.LapComparison{}}, getComparacionVueltas},
+	{openapi.Operacion{Metodo: "GET", Path: "/temporada/resumen", Resumen: "Top 3 de la temporada 2024", Parametros: paramsResumen, Respuesta: SeasonSummaryV1{}}, getResumenTemporada},
+	{openapi.Operacion{Metodo: "GET", Path: "/estadisticas", Resumen: "Ranking de pilotos en una estadística", Parametros: paramsEstadisticas, Respuesta: client.Leaderboard{}}, getEstadisticas},
+	{openapi.Operacion{Metodo: "GET", Path: "/estadisticas/metricas", Resumen: "Estadísticas disponibles para el ranking", Respuesta: client.MetricList{}}, getMetricas},
+	{openapi.Operacion{Metodo: "GET", Path: "/equipo/fiabilidad", Resumen: "Abandonos y fiabilidad de cada equipo", Parametros: paramsFiabilidad, Respuesta: client.TeamReliabilityReport{}}, getFiabilidad},
+	{openapi.Operacion{Metodo: "GET", Path: "/equipo/detalle/:id/companeros", Resumen: "Comparación entre los compañeros de un equipo", Parametros: paramsCompaneros, Respuesta: client.TeamTeammates{}}, getCompaneros},
+}
+
+var rutasV2 = []ruta{
+	{openapi.Operacion{Metodo: "GET", Path: "/drivers", Resumen: "List drivers", Parametros: paramsPilotos, Respuesta: client.DriverList{}}, getDrivers},
+	{openapi.Operacion{Metodo: "GET", Path: "/drivers/:driver_number", Resumen: "Driver results and performance summary", Parametros: []openapi.Parametro{{Nombre: "driver_number", En: "path", Tipo: "integer"}}, Respuesta: client.DriverDetail{}}, getDriverDetailV2},
+	{openapi.Operacion{Metodo: "GET", Path: "/drivers/:driver_number/season", Resumen: "Driver season round by round", Parametros: paramsTemporadaPiloto, Respuesta: client.DriverSeason{}}, getDriverSeasonV2},
+	{openapi.Operacion{Metodo: "GET", Path: "/drivers/compare", Resumen: "Head-to-head between two drivers", Parametros: paramsComparacion, Respuesta: client.HeadToHead{}}, getComparacion},
+	{openapi.Operacion{Metodo: "GET", Path: "/races", Resumen: "List races", Parametros: paramsCarreras, Respuesta: client.RaceList{}}, getCarreras},
+	{openapi.Operacion{Metodo: "GET", Path: "/races/:session_key", Resumen: "Race podium, fastest lap and top speed", Parametros: []openapi.Parametro{{Nombre: "session_key", En: "path", Tipo: "integer"}}, Respuesta: client.RaceDetail{}}, getRaceDetailV2},
+	{openapi.Operacion{Metodo: "GET", Path: "/meetings", Resumen: "List race weekends", Parametros: paramsGrandesPremios, Respuesta: client.MeetingList{}}, getGrandesPremios},
+	{openapi.Operacion{Metodo: "GET", Path: "/practice/:session_key", Resumen: "Short-run and long-run analysis of a practice session", Parametros: []openapi.Parametro{{Nombre: "session_key", En: "path", Tipo: "integer"}}, Respuesta: client.PracticeAnalysis{}}, getPracticeV2},
+	{openapi.Operacion{Metodo: "GET", Path: "/meetings/:meeting_key", Resumen: "Whole race weekend with every session", Parametros: []openapi.Parametro{{Nombre: "meeting_key", En: "path", Tipo: "integer"}}, Respuesta: client.MeetingDetail{}}, getGranPremio},
+	{openapi.Operacion{Metodo: "GET", Path: "/races/:session_key/pace", Resumen: "Race pace and consistency per driver", Parametros: []openapi.Parametro{{Nombre: "session_key", En: "path", Tipo: "integer"}}, Respuesta: client.RacePace{}}, getRacePaceV2},
+	{openapi.Operacion{Metodo: "GET", Path: "/races/:session_key/leaders", Resumen: "Leader at each lap and laps led per driver", Parametros: []openapi.Parametro{{Nombre: "session_key", En: "path", Tipo: "integer"}}, Respuesta: client.RaceLeaders{}}, getRaceLeadersV2},
+	{openapi.Operacion{Metodo: "GET", Path: "/races/:session_key/gaps", Resumen: "Gap to leader and to the car ahead per lap", Parametros: paramsDiferencias, Respuesta: client.RaceGaps{}}, getRaceGapsV2},
+	{openapi.Operacion{Metodo: "GET", Path: "/races/:session_key/drivers/:driver_number/laps", Resumen: "Lap-by-lap data for a driver in a race", Parametros: paramsVueltas, Respuesta: client.DriverLaps{}}, getVueltas},
+	{openapi.Operacion{Metodo: "GET", Path: "/races/:session_key/drivers/:driver_number/laps/:lap_number/telemetry", Resumen: "Telemetry for one lap, downsampled", Parametros: paramsTelemetria, Respuesta: client.LapTelemetry{}}, getTelemetria},
+	{openapi.Operacion{Metodo: "GET", Path: "/races/:session_key/laps/compare", Resumen: "Overlay of two laps aligned by distance", Parametros: paramsComparacionVueltas, Respuesta: client.LapComparison{}}, getComparacionVueltas},
+	{openapi.Operacion{Metodo: "GET", Path: "/seasons/:year/summary", Resumen: "Season top 3 rankings", Parametros: append([]openapi.Parametro{{Nombre: "year", En: "path", Tipo: "integer"}}, paramsResumen...), Respuesta: client.SeasonSummary{}}, getSeasonSummaryV2},
+	{openapi.Operacion{Metodo: "GET", Path: "/stats", Resumen: "Driver leaderboard for a statistic", Parametros: paramsEstadisticas, Respuesta: client.Leaderboard{}}, getEstadisticas},
+	{openapi.Operacion{Metodo: "GET", Path: "/stats/metrics", Resumen: "Statistics available for the leaderboard", Respuesta: client.MetricList{}}, getMetricas},
+	{openapi.Operacion{Metodo: "GET", Path: "/teams/reliability", Resumen: "Retirements and reliability rate per team", Parametros: paramsFiabilidad, Respuesta: client.TeamReliabilityReport{}}, getFiabilidad},
+	{openapi.Operacion{Metodo: "GET", Path: "/teams/:team_id/teammates", Resumen: "Teammate head-to-heads and points split", Parametros: paramsCompaneros, Respuesta: client.TeamTeammates{}}, getTeammatesV2},
+}
+
+func main() {
+	telemetriaSesiones := flag.String("telemetria", "", "session_key separados por coma de los que se carga la telemetria (/car_data)")
+	telemetriaPilotos := flag.String("telemetria-pilotos", "", "números de piloto separados por coma; por defecto todos los de la sesión")
+	flag.Parse()
+	sesiones, err := listaEnteros(*telemetriaSesiones)
+	if err != nil {
+		log.Fatal("--telemetria: ", err)
+	}
+	pilotos, err := listaEnteros(*telemetriaPilotos)
+	if err != nil {
+		log.Fatal("--telemetria-pilotos: ", err)
+	}
+
+	db, err = sql.Open("sqlite3", "/home/ubuntu/proxydb_mount/proxy.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	spec, err := generarOpenAPI()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cargarDatosDesdeOpenF1()
+	cargarTelemetria(sesiones, pilotos)
+	r := gin.Default()
+	registrarRutas(r.Group("/api/v1"), rutasV1)
+	registrarRutas(r.Group("/api/v2"), rutasV2)
+	r.GET("/api/openapi.json", func(c *gin.Context) {
+		c.JSON(200, spec)
+	})
+	r.Run(":8080")
+}
+
+func registrarRutas(g *gin.RouterGroup, rutas []ruta) {
+	for _, rt := range rutas {
+		g.Handle(rt.Metodo, rt.Path, rt.handler)
+	}
+}
+
+// generarOpenAPI documenta las rutas de ambas versiones con su prefijo.
+func generarOpenAPI() (*openapi.Documento, error) {
+	var ops []openapi.Operacion
+	for _, v := range []struct {
+		prefijo string
+		rutas   []ruta
+	}{{"/api/v1", rutasV1}, {"/api/v2", rutasV2}} {
+		for _, rt := range v.rutas {
+			op := rt.Operacion
+			op.Path = v.prefijo + op.Path
+			op.Tags = []string{strings.TrimPrefix(v.prefijo, "/api/")}
+			ops = append(ops, op)
+		}
+	}
+	return openapi.Generar("Tarea1SD F1 API", "2.0.0", ops)
+}
+
+// leerEntero lee un parametro de path numerico; si no es valido responde 400.
+func leerEntero(c *gin.Context, nombre string) (int, bool) {
+	n, err := strconv.Atoi(c.Param(nombre))
+	if err != nil {
+		c.JSON(400, gin.H{"error": nombre + " debe ser un número"})
+		return 0, false
+	}
+	return n, true
+}
+
+func getDrivers(c *gin.Context) {
+	limit, offset, err := leerPaginacion(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	orden, err := leerOrden(c, map[string]string{
+		"driver_number": "driver_number",
+		"first_name":    "first_name",
+		"last_name":     "last_name",
+		"team_name":     "team_name",
+		"country_code":  "country_code",
+	}, "driver_number", "driver_number")
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	var filtros []string
+	var args []interface{}
+	if team := c.Query("team"); team != "" {
+		filtros = append(filtros, "team_name LIKE '%' || ? || '%'")
+		args = append(args, team)
+	}
+	if country := c.Query("country"); country != "" {
+		filtros = append(filtros, "country_code = ? COLLATE NOCASE")
+		args = append(args, country)
+	}
+	where := clausulaWhere(filtros)
+
+	var total int
+	db.QueryRow("SELECT COUNT(*) FROM drivers"+where, args...).Scan(&total)
+	rows, _ := db.Query("SELECT driver_number, first_name, last_name, name_acronym, team_name, country_code FROM drivers"+where+orden+" LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	defer rows.Close()
+	list := []client.Driver{}
+	for rows.Next() {
+		var d client.Driver
+		rows.Scan(&d.DriverNumber, &d.FirstName, &d.LastName, &d.NameAcronym, &d.TeamName, &d.CountryCode)
+		list = append(list, d)
+	}
+	c.JSON(200, client.DriverList{
+		Data:       list,
+		Pagination: nuevaPaginacion(total, limit, offset),
+	})
+}
+
+func getDriverDetail(c *gin.Context) {
+	driverID := c.Param("id")
+	driverNumber, ok := leerEntero(c, "id")
+	if !ok {
+		return
+	}
+	d := consultarDetallePiloto(driverNumber)
+	c.JSON(200, DriverDetailV1{
+		DriverID:           driverID,
+		PerformanceSummary: d.PerformanceSummary,
+		RaceResults:        d.RaceResults,
+	})
+}
+
+func getDriverDetailV2(c *gin.Context) {
+	driverNumber, ok := leerEntero(c, "driver_number")
+	if !ok {
+		return
+	}
+	c.JSON(200, consultarDetallePiloto(driverNumber))
+}
+
+func getTemporadaPiloto(c *gin.Context) {
+	driverNumber, ok := leerEntero(c, "id")
+	if !ok {
+		return
+	}
+	responderTemporadaPiloto(c, driverNumber)
+}
+
+func getDriverSeasonV2(c *gin.Context) {
+	driverNumber, ok := leerEntero(c, "driver_number")
+	if !ok {
+		return
+	}
+	responderTemporadaPiloto(c, driverNumber)
+}
+
+func responderTemporadaPiloto(c *gin.Context, driverNumber int) {
+	year, err := strconv.Atoi(c.DefaultQuery("year", "2024"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "year debe ser un número"})
+		return
+	}
+	temporada, err := consultarTemporadaPiloto(driverNumber, year)
+	if err == sql.ErrNoRows {
+		c.JSON(404, gin.H{"error": "no existe el piloto"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, temporada)
+}
+
+// consultarTemporadaPiloto recorre las carreras del año en orden y acumula
+// los puntos de todos los pilotos para saber el puesto en el campeonato
+// despues de cada fecha. Los puntos de un sprint se suman a la carrera
+// siguiente, que es la del mismo fin de semana; si el sprint es lo ultimo
+// cargado del año queda como una fecha propia. Los compañeros son los otros
+// pilotos del mismo equipo en esa sesion; en cada fecha se toma el que corrio.
+func consultarTemporadaPiloto(driverNumber, year int) (client.DriverSeason, error) {
+	temporada := client.DriverSeason{DriverNumber: driverNumber, Year: year, Rounds: []client.SeasonRound{}}
+	err := db.QueryRow("SELECT first_name || ' ' || last_name, team_name FROM drivers WHERE driver_number = ?", driverNumber).Scan(&temporada.Driver, &temporada.TeamName)
+	if err != nil {
+		return temporada, err
+	}
+
+	// Candidatos a compañero; en cada fecha se filtran por el equipo que
+	// tenia cada uno en esa sesion.
+	var companeros []client.TeammateRound
+	rows, err := db.Query(`
+		SELECT driver_number, first_name || ' ' || last_name
+		FROM drivers
+		WHERE driver_number != ?
+		ORDER BY driver_number
+	`, driverNumber)
+	if err != nil {
+		return temporada, err
+	}
+	for rows.Next() {
+		var t client.TeammateRound
+		rows.Scan(&t.DriverNumber, &t.Driver)
+		companeros = append(companeros, t)
+	}
+	rows.Close()
+
+	type sesion struct {
+		key      int
+		nombre   string
+		circuito string
+		fecha    string
+	}
+	var sesiones []sesion
+	rows, err = db.Query(`
+		SELECT session_key, session_name, circuit_short_name, date_start
+		FROM sessions
+		WHERE year = ? AND session_type = 'Race'
+		ORDER BY date_start
+	`, year)
+	if err != nil {
+		return temporada, err
+	}
+	for rows.Next() {
+		var s sesion
+		rows.Scan(&s.key, &s.nombre, &s.circuito, &s.fecha)
+		sesiones = append(sesiones, s)
+	}
+	rows.Close()
+
+	acumulados := map[int]int{}
+	posicionCampeonato := func() int {
+		posicion := 1
+		for numero, p := range acumulados {
+			if numero != driverNumber && p > acumulados[driverNumber] {
+				posicion++
+			}
+		}
+		return posicion
+	}
+	sprint := map[int]int{}
+	var ultimoSprint sesion
+	for _, s := range sesiones {
+		clasificacion := consultarClasificacion(s.key)
+		if s.nombre == client.SessionSprint {
+			for _, r := range clasificacion {
+				sprint[r.DriverNumber] += r.Points
+			}
+			ultimoSprint = s
+			continue
+		}
+
+		puntos := map[int]int{}
+		resultados := map[int]client.ResultEntry{}
+		for _, r := range clasificacion {
+			puntos[r.DriverNumber] += r.Points
+			resultados[r.DriverNumber] = r
+		}
+		for numero, p := range sprint {
+			puntos[numero] += p
+		}
+		for numero, p := range puntos {
+			acumulados[numero] += p
+		}
+
+		ronda := client.SeasonRound{
+			Round:                len(temporada.Rounds) + 1,
+			SessionKey:           s.key,
+			CircuitShortName:     s.circuito,
+			DateStart:            s.fecha,
+			Points:               puntos[driverNumber],
+			SprintPoints:         sprint[driverNumber],
+			CumulativePoints:     acumulados[driverNumber],
+			ChampionshipPosition: posicionCampeonato(),
+		}
+		sprint = map[int]int{}
+		if r, ok := resultados[driverNumber]; ok {
+			pos := r.Position
+			ronda.Position, ronda.Status = &pos, r.Status
+		}
+		equipos := equiposPorSesion(s.key)
+		equipo, ok := equipos[driverNumber]
+		if !ok {
+			equipo = temporada.TeamName
+		}
+		for _, companero := range companeros {
+			r, ok := resultados[companero.DriverNumber]
+			if !ok || equipos[companero.DriverNumber] != equipo {
+				continue
+			}
+			pos := r.Position
+			companero.Position = &pos
+			companero.Points = puntos[companero.DriverNumber]
+			companero.CumulativePoints = acumulados[companero.DriverNumber]
+			if ronda.Position != nil {
+				switch {
+				case *ronda.Position <= 0 || pos <= 0 || *ronda.Position == pos:
+				case *ronda.Position < pos:
+					companero.Ahead = true
+					temporada.TeammateHeadToHead.A++
+				default:
+					temporada.TeammateHeadToHead.B++
+				}
+			}
+			ronda.Teammate = &companero
+			break
+		}
+		temporada.Rounds = append(temporada.Rounds, ronda)
+	}
+	// Un sprint sin carrera despues (todavia no se cargo) es una fecha solo
+	// con los puntos del sprint.
+	if len(sprint) > 0 {
+		for numero, p := range sprint {
+			acumulados[numero] += p
+		}
+		temporada.Rounds = append(temporada.Rounds, client.SeasonRound{
+			Round:                len(temporada.Rounds) + 1,
+			SessionKey:           ultimoSprint.key,
+			CircuitShortName:     ultimoSprint.circuito,
+			DateStart:            ultimoSprint.fecha,
+			Points:               sprint[driverNumber],
+			SprintPoints:         sprint[driverNumber],
+			CumulativePoints:     acumulados[driverNumber],
+			ChampionshipPosition: posicionCampeonato(),
+		})
+	}
+	if n := len(temporada.Rounds); n > 0 {
+		temporada.Points = temporada.Rounds[n-1].CumulativePoints
+		temporada.ChampionshipPosition = temporada.Rounds[n-1].ChampionshipPosition
+	}
+	return temporada, nil
+}
+
+func consultarDetallePiloto(driverNumber int) client.DriverDetail {
+	// El estado, la vuelta rapida, los puntos y la posicion en la clasificacion
+	// (que tiene en cuenta descalificaciones) salen de results, que puede no
+	// estar cargada.
+	type resultado struct {
+		posicion     int
+		estado       string
+		puntos       int
+		vueltaRapida bool
+	}
+	resultados := map[int]resultado{}
+	if res, err := db.Query("SELECT session_key, position, status, points, fastest_lap FROM results WHERE driver_number = ?", driverNumber); err == nil {
+		for res.Next() {
+			var key int
+			var r resultado
+			res.Scan(&key, &r.posicion, &r.estado, &r.puntos, &r.vueltaRapida)
+			resultados[key] = r
+		}
+		res.Close()
+	}
+
+	vueltasLideradas := map[int]int{}
+	if res, err := db.Query("SELECT session_key, COUNT(*) FROM lap_leaders WHERE driver_number = ? GROUP BY session_key", driverNumber); err == nil {
+		for res.Next() {
+			var key, n int
+			res.Scan(&key, &n)
+			vueltasLideradas[key] = n
+		}
+		res.Close()
+	}
+
+	rows, _ := db.Query(`
+		SELECT 
+			s.session_key, 
+			s.circuit_short_name, 
+			s.session_name, 
+			p.position, 
+			COALESCE(MAX(l.st_speed), 0), 
+			COALESCE(MIN(CASE 
+				WHEN l.lap_duration > 0 
+				  AND l.duration_sector_1 > 0 
+				  AND l.duration_sector_2 > 0 
+				  AND l.duration_sector_3 > 0 
+				THEN l.lap_duration 
+				ELSE NULL 
+			END), 0)
+		FROM positions p
+		JOIN sessions s ON p.session_key = s.session_key
+		LEFT JOIN laps l ON l.session_key = p.session_key AND l.driver_number = p.driver_number
+		WHERE p.driver_number = ? AND s.session_type = 'Race'
+		GROUP BY s.session_key
+	`, driverNumber)
+	defer rows.Close()
+
+	detalle := client.DriverDetail{DriverNumber: driverNumber, RaceResults: []client.RaceResult{}, SprintResults: []client.RaceResult{}}
+	resumen := &detalle.PerformanceSummary
+	for rows.Next() {
+		var r client.RaceResult
+		rows.Scan(&r.SessionKey, &r.CircuitShortName, &r.Race, &r.Position, &r.MaxSpeed, &r.BestLapDuration)
+		res, ok := resultados[r.SessionKey]
+		if ok {
+			r.Position, r.Status, r.FastestLap = res.posicion, res.estado, res.vueltaRapida
+		}
+		r.LapsLed = vueltasLideradas[r.SessionKey]
+		if r.MaxSpeed > resumen.MaxSpeed {
+			resumen.MaxSpeed = r.MaxSpeed
+		}
+		if r.Race == client.SessionSprint {
+			if r.Position == 1 {
+				resumen.SprintWins++
+			}
+			resumen.SprintPoints += res.puntos
+			detalle.SprintResults = append(detalle.SprintResults, r)
+			continue
+		}
+		resumen.Points += res.puntos
+		resumen.LapsLed += r.LapsLed
+		if r.Status == client.StatusDNF {
+			resumen.DNFs++
+		}
+		if r.Status != client.StatusDNS {
+			resumen.RacesStarted++
+		}
+		if r.Position == 1 {
+			resumen.Wins++
+		}
+		if r.Position <= 3 {
+			resumen.Top3Finishes++
+		}
+		detalle.RaceResults = append(detalle.RaceResults, r)
+	}
+	// Sin results no se sabe que carreras termino, y la tasa seria siempre 1.
+	if len(resultados) > 0 && resumen.RacesStarted > 0 {
+		tasa := float64(resumen.RacesStarted-resumen.DNFs) / float64(resumen.RacesStarted)
+		resumen.ReliabilityRate = &tasa
+	}
+	return detalle
+}
+
+func getCarreras(c *gin.Context) {
+	limit, offset, err := leerPaginacion(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	orden, err := leerOrden(c, map[string]string{
+		"session_key":        "session_key",
+		"country_name":       "country_name",
+		"date_start":         "date_start",
+		"year":               "year",
+		"circuit_short_name": "circuit_short_name",
+		"session_name":       "session_name",
+	}, "date_start", "session_key")
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	filtros := []string{"session_type = 'Race'"}
+	var args []interface{}
+	switch c.DefaultQuery("type", "race") {
+	case "race":
+		filtros = append(filtros, "session_name = ?")
+		args = append(args, client.SessionRace)
+	case "sprint":
+		filtros = append(filtros, "session_name = ?")
+		args = append(args, client.SessionSprint)
+	case "all":
+	default:
+		c.JSON(400, gin.H{"error": "type debe ser race, sprint o all"})
+		return
+	}
+	if country := c.Query("country"); country != "" {
+		filtros = append(filtros, "country_name LIKE '%' || ? || '%'")
+		args = append(args, country)
+	}
+	if year := c.Query("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "year debe ser un número"})
+			return
+		}
+		filtros = append(filtros, "year = ?")
+		args = append(args, y)
+	}
+	if circuit := c.Query("circuit"); circuit != "" {
+		filtros = append(filtros, "circuit_short_name LIKE '%' || ? || '%'")
+		args = append(args, circuit)
+	}
+	where := clausulaWhere(filtros)
+
+	var total int
+	db.QueryRow("SELECT COUNT(*) FROM sessions"+where, args...).Scan(&total)
+	rows, _ := db.Query(`
+		SELECT session_key, session_name, country_name, date_start, year, circuit_short_name
+		FROM sessions`+where+orden+" LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	defer rows.Close()
+	list := []client.Race{}
+	for rows.Next() {
+		var r client.Race
+		rows.Scan(&r.SessionKey, &r.SessionName, &r.CountryName, &r.DateStart, &r.Year, &r.CircuitShortName)
+		list = append(list, r)
+	}
+	c.JSON(200, client.RaceList{
+		Data:       list,
+		Pagination: nuevaPaginacion(total, limit, offset),
+	})
+}
+
+func getGrandesPremios(c *gin.Context) {
+	limit, offset, err := leerPaginacion(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	orden, err := leerOrden(c, map[string]string{
+		"meeting_key":  "meeting_key",
+		"meeting_name": "meeting_name",
+		"country_name": "country_name",
+		"date_start":   "date_start",
+		"year":         "year",
+	}, "date_start", "meeting_key")
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	var filtros []string
+	var args []interface{}
+	if country := c.Query("country"); country != "" {
+		filtros = append(filtros, "country_name LIKE '%' || ? || '%'")
+		args = append(args, country)
+	}
+	if year := c.Query("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "year debe ser un número"})
+			return
+		}
+		filtros = append(filtros, "year = ?")
+		args = append(args, y)
+	}
+	where := clausulaWhere(filtros)
+
+	var total int
+	db.QueryRow("SELECT COUNT(*) FROM meetings"+where, args...).Scan(&total)
+	rows, err := db.Query(`
+		SELECT meeting_key, meeting_name, meeting_official_name, country_name, location, circuit_short_name, date_start, year
+		FROM meetings`+where+orden+" LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+	list := []client.Meeting{}
+	for rows.Next() {
+		var m client.Meeting
+		rows.Scan(&m.MeetingKey, &m.MeetingName, &m.OfficialName, &m.CountryName, &m.Location, &m.CircuitShortName, &m.DateStart, &m.Year)
+		list = append(list, m)
+	}
+	c.JSON(200, client.MeetingList{
+		Data:       list,
+		Pagination: nuevaPaginacion(total, limit, offset),
+	})
+}
+
+func getGranPremio(c *gin.Context) {
+	meetingKey, ok := leerEntero(c, "meeting_key")
+	if !ok {
+		return
+	}
+	detalle, err := consultarGranPremio(meetingKey)
+	if err == sql.ErrNoRows {
+		c.JSON(404, gin.H{"error": "no existe el gran premio"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, detalle)
+}
+
+// consultarGranPremio arma el fin de semana con sus sesiones en orden. Para
+// carreras y sprints se usa el podio de la clasificacion; para practicas y
+// clasificaciones, las tres primeras posiciones.
+func consultarGranPremio(meetingKey int) (client.MeetingDetail, error) {
+	detalle := client.MeetingDetail{Sessions: []client.WeekendSession{}}
+	m := &detalle.Meeting
+	err := db.QueryRow(`
+		SELECT meeting_key, meeting_name, meeting_official_name, country_name, location, circuit_short_name, date_start, year
+		FROM meetings
+		WHERE meeting_key = ?
+	`, meetingKey).Scan(&m.MeetingKey, &m.MeetingName, &m.OfficialName, &m.CountryName, &m.Location, &m.CircuitShortName, &m.DateStart, &m.Year)
+	if err != nil {
+		return detalle, err
+	}
+
+	rows, err := db.Query(`
+		SELECT session_key, session_name, session_type, date_start
+		FROM sessions
+		WHERE meeting_key = ?
+		ORDER BY date_start
+	`, meetingKey)
+	if err != nil {
+		return detalle, err
+	}
+	for rows.Next() {
+		var s client.WeekendSession
+		rows.Scan(&s.SessionKey, &s.SessionName, &s.SessionType, &s.DateStart)
+		detalle.Sessions = append(detalle.Sessions, s)
+	}
+	rows.Close()
+
+	for i := range detalle.Sessions {
+		s := &detalle.Sessions[i]
+		s.Top3 = []client.ClassificationEntry{}
+		if s.SessionType == "Race" {
+			s.Top3 = podio(consultarClasificacion(s.SessionKey))
+		} else {
+			s.Top3 = primerasPosiciones(s.SessionKey, 3)
+		}
+		if vr, ok := consultarVueltaRapida(s.SessionKey); ok {
+			s.FastestLap = &vr
+		}
+	}
+	return detalle, nil
+}
+
+// primerasPosiciones devuelve las n primeras posiciones finales de una sesion.
+func primerasPosiciones(sessionKey, n int) []client.ClassificationEntry {
+	lista := []client.ClassificationEntry{}
+	rows, err := db.Query(`
+		SELECT p.position, p.driver_number, d.first_name || ' ' || d.last_name, d.team_name, d.country_code
+		FROM positions p
+		JOIN drivers d ON d.driver_number = p.driver_number
+		WHERE p.session_key = ?
+		ORDER BY p.position
+		LIMIT ?
+	`, sessionKey, n)
+	if err != nil {
+		return lista
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e client.ClassificationEntry
+		rows.Scan(&e.Position, &e.DriverNumber, &e.Driver, &e.TeamName, &e.CountryCode)
+		lista = append(lista, e)
+	}
+	return lista
+}
+
+func getCarreraDetail(c *gin.Context) {
+	sessionID := c.Param("id")
+	sessionKey, ok := leerEntero(c, "id")
+	if !ok {
+		return
+	}
+	d := consultarDetalleCarrera(sessionKey)
+	var results []ResultadoV1
+	for _, p := range d.Podium {
+		results = append(results, ResultadoV1{Position: p.Position, Driver: p.Driver, Team: p.TeamName, Country: p.CountryCode})
+	}
+	ultimo := ResultadoV1{Position: "Ultimo"}
+	if d.LastPlace != nil {
+		ultimo.Driver, ultimo.Team, ultimo.Country = d.LastPlace.Driver, d.LastPlace.TeamName, d.LastPlace.CountryCode
+	}
+	c.JSON(200, RaceDetailV1{
+		RaceID:      sessionID,
+		SessionName: d.SessionName,
+		Results:     append(results, ultimo),
+		FastestLap: FastestLapV1{
+			Driver:    d.FastestLap.Driver,
+			TotalTime: d.FastestLap.LapDuration,
+			Sector1:   d.FastestLap.Sector1,
+			Sector2:   d.FastestLap.Sector2,
+			Sector3:   d.FastestLap.Sector3,
+		},
+		Podium:         d.Podium,
+		Classification: d.Classification,
+		IdealLap:       d.IdealLap,
+		MaxSpeed:       d.MaxSpeed,
+		Strategy:       d.Strategy,
+		Weather:        d.Weather,
+	})
+}
+
+func getRaceDetailV2(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "session_key")
+	if !ok {
+		return
+	}
+	c.JSON(200, consultarDetalleCarrera(sessionKey))
+}
+
+func consultarDetalleCarrera(sessionKey int) client.RaceDetail {
+	detalle := client.RaceDetail{SessionKey: sessionKey, Podium: []client.ClassificationEntry{}}
+	db.QueryRow("SELECT session_name FROM sessions WHERE session_key = ?", sessionKey).Scan(&detalle.SessionName)
+	detalle.Classification = consultarClasificacion(sessionKey)
+	detalle.Podium = podio(detalle.Classification)
+	if n := len(detalle.Classification); n > 0 {
+		ultimo := detalle.Classification[n-1].ClassificationEntry
+		detalle.LastPlace = &ultimo
+	}
+	detalle.FastestLap, _ = consultarVueltaRapida(sessionKey)
+	detalle.IdealLap = consultarVueltaIdeal(sessionKey)
+
+	speedRow := db.QueryRow(`
+		SELECT d.first_name || ' ' || d.last_name, MAX(l.st_speed)
+		FROM laps l
+		JOIN drivers d ON d.driver_number = l.driver_number
+		WHERE l.session_key = ?
+	`, sessionKey)
+	speedRow.Scan(&detalle.MaxSpeed.Driver, &detalle.MaxSpeed.SpeedKmh)
+
+	// La trampa de velocidad (st_speed) mide en un solo punto del circuito. Si
+	// hay telemetria de todos los pilotos se usa la velocidad maxima real.
+	telemetriaRow := db.QueryRow(`
+		SELECT d.first_name || ' ' || d.last_name, MAX(c.max_speed)
+		FROM car_data c
+		JOIN drivers d ON d.driver_number = c.driver_number
+		WHERE c.session_key = ?
+		  AND (SELECT COUNT(DISTINCT driver_number) FROM car_data WHERE session_key = ?) =
+		      (SELECT COUNT(DISTINCT driver_number) FROM laps WHERE session_key = ?)
+	`, sessionKey, sessionKey, sessionKey)
+	var piloto sql.NullString
+	var maxima sql.NullFloat64
+	if telemetriaRow.Scan(&piloto, &maxima) == nil && maxima.Valid {
+		detalle.MaxSpeed = client.MaxSpeed{Driver: piloto.String, SpeedKmh: maxima.Float64}
+	}
+	detalle.Strategy = consultarEstrategias(sessionKey)
+	detalle.Weather = consultarClima(sessionKey)
+	return detalle
+}
+
+// Puntos por posicion (reglamento 2024) y el punto extra por vuelta rapida
+// para quien termine entre los diez primeros.
+var puntosCarrera = []int{25, 18, 15, 12, 10, 8, 6, 4, 2, 1}
+
+// puntosSprint son los puntos de los ocho primeros de un sprint, que no da
+// punto por vuelta rapida.
+var puntosSprint = []int{8, 7, 6, 5, 4, 3, 2, 1}
+
+const puntoVueltaRapida = 1
+
+func clasificado(estado string) bool {
+	return estado == client.StatusFinished || strings.HasPrefix(estado, "+")
+}
+
+// clasificadoEnCarrera dice si un piloto entra en la clasificacion: termino
+// (aunque sea doblado) o abandono despues de completar al menos el 90% de
+// las vueltas del ganador, redondeado hacia abajo.
+func clasificadoEnCarrera(r client.ResultEntry, vueltasGanador int) bool {
+	if clasificado(r.Status) {
+		return true
+	}
+	return r.Status == client.StatusDNF && vueltasGanador > 0 && r.LapsCompleted >= vueltasGanador*9/10
+}
+
+// podio devuelve los tres primeros de una clasificacion que entran en la
+// distancia de carrera.
+func podio(clasificacion []client.ResultEntry) []client.ClassificationEntry {
+	podio := []client.ClassificationEntry{}
+	for _, r := range clasificacion {
+		if r.Position <= 3 && clasificadoEnCarrera(r, clasificacion[0].LapsCompleted) {
+			podio = append(podio, r.ClassificationEntry)
+		}
+	}
+	return podio
+}
+
+type vueltasPiloto struct {
+	completadas int
+	fin         time.Time // cuando termino su ultima vuelta
+}
+
+// consultarClasificacion lee la clasificacion guardada en results. Si la
+// carrera todavia no se materializo (o la tabla no existe) se calcula.
+func consultarClasificacion(sessionKey int) []client.ResultEntry {
+	rows, err := db.Query(`
+		SELECT r.position, r.driver_number, d.first_name || ' ' || d.last_name, d.team_name, d.country_code,
+		       r.status, r.laps_completed, r.gap_to_winner, r.points, r.fastest_lap
+		FROM results r
+		JOIN drivers d ON d.driver_number = r.driver_number
+		WHERE r.session_key = ?
+		ORDER BY r.position
+	`, sessionKey)
+	if err != nil {
+		return calcularClasificacion(db, sessionKey)
+	}
+	defer rows.Close()
+	clasificacion := []client.ResultEntry{}
+	for rows.Next() {
+		var r client.ResultEntry
+		var gap sql.NullFloat64
+		rows.Scan(&r.Position, &r.DriverNumber, &r.Driver, &r.TeamName, &r.CountryCode, &r.Status, &r.LapsCompleted, &gap, &r.Points, &r.FastestLap)
+		if gap.Valid {
+			r.GapToWinner = &gap.Float64
+		}
+		clasificacion = append(clasificacion, r)
+	}
+	if len(clasificacion) == 0 {
+		return calcularClasificacion(db, sessionKey)
+	}
+	return clasificacion
+}
+
+// guardarResultados materializa en results la clasificacion de cada carrera
+// cargada. Corre despues de las cargas de posiciones, vueltas y direccion de
+// carrera, que son los datos de los que sale el estado de cada piloto.
+func guardarResultados() {
+	for _, key := range sesionesCargadas("Race") {
+		tx, err := db.Begin()
+		if err != nil {
+			log.Println("Error resultados:", err)
+			return
+		}
+		// Una carrera se guarda completa o no se guarda: si algo falla se
+		// descarta y consultarClasificacion la sigue calculando.
+		if err := guardarClasificacion(tx, key); err != nil {
+			log.Println("Error guardando resultado:", err)
+			tx.Rollback()
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			log.Println("Error resultados:", err)
+		}
+	}
+}
+
+// guardarClasificacion reemplaza dentro de tx las filas de results de una
+// carrera. La clasificacion tambien se lee dentro de tx.
+func guardarClasificacion(tx *sql.Tx, sessionKey int) error {
+	if _, err := tx.Exec("DELETE FROM results WHERE session_key = ?", sessionKey); err != nil {
+		return err
+	}
+	insert := `INSERT OR REPLACE INTO results (session_key, driver_number, position, status, laps_completed, gap_to_winner, points, fastest_lap) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, r := range calcularClasificacion(tx, sessionKey) {
+		if _, err := tx.Exec(insert, sessionKey, r.DriverNumber, r.Position, r.Status, r.LapsCompleted, r.GapToWinner, r.Points, r.FastestLap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// existeTabla dice si la base tiene la tabla. Una base creada con una
+// version anterior de tablas.go puede no tener las tablas nuevas.
+func existeTabla(q consultor, nombre string) bool {
+	var n int
+	q.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", nombre).Scan(&n)
+	return n > 0
+}
+
+// consultor es lo que *sql.DB y *sql.Tx tienen en comun para leer, asi las
+// consultas que arman la clasificacion corren dentro de la transaccion que la
+// guarda.
+type consultor interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// pilotosConMensaje devuelve los pilotos que tienen un mensaje de direccion
+// de carrera que contiene texto, por ejemplo "DISQUALIFIED" o "RETIRED".
+func pilotosConMensaje(q consultor, sessionKey int, texto string) map[int]bool {
+	pilotos := map[int]bool{}
+	rows, err := q.Query(`
+		SELECT DISTINCT driver_number
+		FROM race_control
+		WHERE session_key = ? AND driver_number IS NOT NULL AND UPPER(message) LIKE '%' || ? || '%'
+	`, sessionKey, texto)
+	if err != nil {
+		return pilotos
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var numero int
+		rows.Scan(&numero)
+		pilotos[numero] = true
+	}
+	return pilotos
+}
+
+// calcularClasificacion arma la clasificacion completa a partir de la
+// posicion final, las vueltas y los mensajes de direccion de carrera. Un
+// piloto con menos vueltas que el ganador termino doblado si cruzo la meta
+// despues que el ganador, y abandono si su ultima vuelta fue antes o si
+// direccion de carrera informo el abandono. Un abandono igual se clasifica
+// (con su estado DNF) si completo al menos el 90% de las vueltas del
+// ganador, redondeado hacia abajo; un doblado que sigue en pista siempre se
+// clasifica.
+func calcularClasificacion(q consultor, sessionKey int) []client.ResultEntry {
+	clasificacion := []client.ResultEntry{}
+	rows, _ := q.Query(`
+		SELECT p.position, p.driver_number, d.first_name || ' ' || d.last_name, d.team_name, d.country_code
+		FROM positions p
+		JOIN drivers d ON d.driver_number = p.driver_number
+		WHERE p.session_key = ?
+		ORDER BY p.position ASC
+	`, sessionKey)
+	defer rows.Close()
+	for rows.Next() {
+		var r client.ResultEntry
+		rows.Scan(&r.Position, &r.DriverNumber, &r.Driver, &r.TeamName, &r.CountryCode)
+		clasificacion = append(clasificacion, r)
+	}
+
+	vueltas := map[int]*vueltasPiloto{}
+	lapRows, _ := q.Query(`
+		SELECT driver_number, lap_number, date_start, lap_duration
+		FROM laps
+		WHERE session_key = ?
+	`, sessionKey)
+	defer lapRows.Close()
+	for lapRows.Next() {
+		var numero, vuelta int
+		var inicio sql.NullString
+		var duracion float64
+		lapRows.Scan(&numero, &vuelta, &inicio, &duracion)
+		v, ok := vueltas[numero]
+		if !ok {
+			v = &vueltasPiloto{}
+			vueltas[numero] = v
+		}
+		if vuelta > v.completadas {
+			v.completadas = vuelta
+		}
+		if desde, err := time.Parse(time.RFC3339, inicio.String); err == nil {
+			if fin := desde.Add(time.Duration(duracion * float64(time.Second))); fin.After(v.fin) {
+				v.fin = fin
+			}
+		}
+	}
+
+	descalificados := pilotosConMensaje(q, sessionKey, "DISQUALIFIED")
+	retirados := pilotosConMensaje(q, sessionKey, "RETIRED")
+
+	var vueltaRapida int
+	if vr, ok := leerVueltaRapida(q, sessionKey); ok {
+		vueltaRapida = vr.piloto
+	}
+
+	ganador := &vueltasPiloto{}
+	if len(clasificacion) > 0 && vueltas[clasificacion[0].DriverNumber] != nil {
+		ganador = vueltas[clasificacion[0].DriverNumber]
+	}
+	for i := range clasificacion {
+		r := &clasificacion[i]
+		v := vueltas[r.DriverNumber]
+		switch {
+		case descalificados[r.DriverNumber]:
+			r.Status = client.StatusDSQ
+		case v == nil:
+			r.Status = client.StatusDNS
+		case retirados[r.DriverNumber]:
+			r.Status = client.StatusDNF
+		case v.completadas >= ganador.completadas:
+			r.Status = client.StatusFinished
+		case !v.fin.IsZero() && !ganador.fin.IsZero() && v.fin.Before(ganador.fin):
+			r.Status = client.StatusDNF
+		case v.fin.IsZero() || ganador.fin.IsZero():
+			// Sin horarios no se puede distinguir un doblado de un abandono.
+			r.Status = client.StatusDNF
+		case ganador.completadas-v.completadas == 1:
+			r.Status = "+1 Lap"
+		default:
+			r.Status = fmt.Sprintf("+%d Laps", ganador.completadas-v.completadas)
+		}
+		if v != nil {
+			r.LapsCompleted = v.completadas
+			if i > 0 && r.Status == client.StatusFinished && !v.fin.IsZero() && !ganador.fin.IsZero() {
+				gap := v.fin.Sub(ganador.fin).Seconds()
+				r.GapToWinner = &gap
+			}
+		}
+		r.FastestLap = r.DriverNumber == vueltaRapida
+	}
+
+	// Primero los clasificados (por vueltas completadas), despues los
+	// abandonos, descalificados y los que no largaron. Las posiciones y los
+	// puntos se asignan en ese orden.
+	grupo := func(r client.ResultEntry) int {
+		switch {
+		case clasificadoEnCarrera(r, ganador.completadas):
+			return 0
+		case r.Status == client.StatusDNF:
+			return 1
+		case r.Status == client.StatusDSQ:
+			return 2
+		}
+		return 3
+	}
+	sort.SliceStable(clasificacion, func(i, j int) bool {
+		a, b := clasificacion[i], clasificacion[j]
+		if grupo(a) != grupo(b) {
+			return grupo(a) < grupo(b)
+		}
+		return grupo(a) == 0 && a.LapsCompleted > b.LapsCompleted
+	})
+	var nombre string
+	q.QueryRow("SELECT session_name FROM sessions WHERE session_key = ?", sessionKey).Scan(&nombre)
+	puntos := puntosCarrera
+	if nombre == client.SessionSprint {
+		puntos = puntosSprint
+	}
+	for i := range clasificacion {
+		r := &clasificacion[i]
+		r.Position = i + 1
+		if clasificadoEnCarrera(*r, ganador.completadas) && i < len(puntos) {
+			r.Points = puntos[i]
+			if r.FastestLap && nombre != client.SessionSprint {
+				r.Points += puntoVueltaRapida
+			}
+		}
+	}
+	return clasificacion
+}
+
+// vueltaRapidaSesion es la vuelta valida mas rapida de una sesion.
+type vueltaRapidaSesion struct {
+	piloto   int
+	vuelta   int
+	duracion float64
+}
+
+// calcularVueltaRapida busca la vuelta mas rapida con los tres sectores. Si
+// dos vueltas tienen el mismo tiempo gana la que se marco primero, como en el
+// reglamento.
+func calcularVueltaRapida(q consultor, sessionKey int) (vueltaRapidaSesion, bool) {
+	var vr vueltaRapidaSesion
+	err := q.QueryRow(`
+		SELECT driver_number, lap_number, lap_duration
+		FROM laps
+		WHERE session_key = ?
+		  AND lap_duration > 0
+		  AND duration_sector_1 > 0
+		  AND duration_sector_2 > 0
+		  AND duration_sector_3 > 0
+		ORDER BY lap_duration, COALESCE(date_start, '') = '', date_start, driver_number
+		LIMIT 1
+	`, sessionKey).Scan(&vr.piloto, &vr.vuelta, &vr.duracion)
+	return vr, err == nil
+}
+
+// leerVueltaRapida lee la vuelta rapida guardada en session_fastest_lap. Si
+// la sesion todavia no se materializo (o la tabla no existe) se calcula.
+func leerVueltaRapida(q consultor, sessionKey int) (vueltaRapidaSesion, bool) {
+	var vr vueltaRapidaSesion
+	err := q.QueryRow(`
+		SELECT driver_number, lap_number, lap_duration
+		FROM session_fastest_lap
+		WHERE session_key = ?
+	`, sessionKey).Scan(&vr.piloto, &vr.vuelta, &vr.duracion)
+	if err != nil {
+		return calcularVueltaRapida(q, sessionKey)
+	}
+	return vr, true
+}
+
+// guardarVueltasRapidas materializa en session_fastest_lap la vuelta rapida
+// de cada sesion cargada. Corre despues de la carga de vueltas. Si una sesion
+// ya no tiene vueltas validas se borra la fila que pudo quedar de otra carga.
+func guardarVueltasRapidas() {
+	insert := `INSERT OR REPLACE INTO session_fastest_lap (session_key, driver_number, lap_number, lap_duration) VALUES (?, ?, ?, ?)`
+	for _, key := range sesionesCargadas("") {
+		vr, ok := calcularVueltaRapida(db, key)
+		if !ok {
+			if _, err := db.Exec("DELETE FROM session_fastest_lap WHERE session_key = ?", key); err != nil {
+				log.Println("Error borrando vuelta rapida:", err)
+			}
+			continue
+		}
+		if _, err := db.Exec(insert, key, vr.piloto, vr.vuelta, vr.duracion); err != nil {
+			log.Println("Error guardando vuelta rapida:", err)
+			continue
+		}
+	}
+}
+
+// contarVueltasRapidas cuenta por piloto las vueltas rapidas de las carreras
+// del año (0 para todas las temporadas). El conteo sale de
+// session_fastest_lap; solo las carreras que no estan ahi (o todas, si la
+// tabla no existe) se calculan con calcularVueltaRapida.
+func contarVueltasRapidas(year int) (map[int]int, error) {
+	conteo := map[int]int{}
+	faltantes := `
+		SELECT s.session_key
+		FROM sessions s
+		WHERE s.session_name = 'Race' AND (?1 = 0 OR s.year = ?1)
+		  AND NOT EXISTS (SELECT 1 FROM session_fastest_lap f WHERE f.session_key = s.session_key)`
+	if !existeTabla(db, "session_fastest_lap") {
+		faltantes = "SELECT session_key FROM sessions WHERE session_name = 'Race' AND (?1 = 0 OR year = ?1)"
+	} else {
+		rows, err := db.Query(`
+			SELECT f.driver_number, COUNT(*)
+			FROM session_fastest_lap f
+			JOIN sessions s ON s.session_key = f.session_key
+			WHERE s.session_name = 'Race' AND (?1 = 0 OR s.year = ?1)
+			GROUP BY f.driver_number
+		`, year)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var numero, n int
+			rows.Scan(&numero, &n)
+			conteo[numero] = n
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := db.Query(faltantes, year)
+	if err != nil {
+		return nil, err
+	}
+	var carreras []int
+	for rows.Next() {
+		var key int
+		rows.Scan(&key)
+		carreras = append(carreras, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, key := range carreras {
+		if vr, ok := calcularVueltaRapida(db, key); ok {
+			conteo[vr.piloto]++
+		}
+	}
+	return conteo, nil
+}
+
+// consultarVueltaRapida devuelve la vuelta valida mas rapida de la sesion con
+// sus sectores, o false si no hay vueltas validas.
+func consultarVueltaRapida(sessionKey int) (client.FastestLap, bool) {
+	var fl client.FastestLap
+	vr, ok := leerVueltaRapida(db, sessionKey)
+	if !ok {
+		return fl, false
+	}
+	err := db.QueryRow(`
+		SELECT d.first_name || ' ' || d.last_name, l.lap_duration, l.duration_sector_1, l.duration_sector_2, l.duration_sector_3
+		FROM laps l
+		JOIN drivers d ON d.driver_number = l.driver_number
+		WHERE l.session_key = ? AND l.driver_number = ? AND l.lap_number = ?
+	`, sessionKey, vr.piloto, vr.vuelta).Scan(&fl.Driver, &fl.LapDuration, &fl.Sector1, &fl.Sector2, &fl.Sector3)
+	return fl, err == nil
+}
+
+func consultarClima(sessionKey int) *client.WeatherSummary {
+	var n int
+	var w client.WeatherSummary
+	err := db.QueryRow(`
+		SELECT COUNT(*), COALESCE(MIN(air_temperature), 0), COALESCE(MAX(air_temperature), 0),
+		       COALESCE(MIN(track_temperature), 0), COALESCE(MAX(track_temperature), 0),
+		       COALESCE(AVG(humidity), 0), COALESCE(MAX(rainfall), 0) > 0
+		FROM weather
+		WHERE session_key = ?
+	`, sessionKey).Scan(&n, &w.AirTemperatureMin, &w.AirTemperatureMax, &w.TrackTemperatureMin, &w.TrackTemperatureMax, &w.HumidityAvg, &w.Rainfall)
+	if err != nil || n == 0 {
+		return nil
+	}
+	return &w
+}
+
+var gravedadPista = map[string]int{
+	client.TrackGreen:  0,
+	client.TrackYellow: 1,
+	client.TrackVSC:    2,
+	client.TrackSC:     3,
+	client.TrackRed:    4,
+}
+
+type periodoPista struct {
+	estado       string
+	desde, hasta time.Time
+}
+
+// consultarPeriodosPista reconstruye a partir de race_control los periodos con
+// bandera amarilla, VSC, safety car o bandera roja. La bandera verde de pista
+// cierra todo lo que este abierto; las amarillas de sector se cierran con el
+// CLEAR de su sector.
+func consultarPeriodosPista(sessionKey int) []periodoPista {
+	rows, err := db.Query(`
+		SELECT date, COALESCE(category, ''), COALESCE(flag, ''), COALESCE(scope, ''), COALESCE(sector, 0), COALESCE(message, '')
+		FROM race_control
+		WHERE session_key = ?
+		ORDER BY date
+	`, sessionKey)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var periodos []periodoPista
+	abiertos := map[string]periodoPista{}
+	abrir := func(clave, estado string, t time.Time) {
+		if _, ok := abiertos[clave]; !ok {
+			abiertos[clave] = periodoPista{estado: estado, desde: t}
+		}
+	}
+	cerrar := func(t time.Time, claves ...string) {
+		for _, clave := range claves {
+			if p, ok := abiertos[clave]; ok {
+				p.hasta = t
+				periodos = append(periodos, p)
+				delete(abiertos, clave)
+			}
+		}
+	}
+	cerrarAmarillas := func(t time.Time) {
+		for clave, p := range abiertos {
+			if p.estado == client.TrackYellow {
+				cerrar(t, clave)
+			}
+		}
+	}
+
+	for rows.Next() {
+		var fecha, categoria, bandera, alcance, mensaje string
+		var sector int
+		rows.Scan(&fecha, &categoria, &bandera, &alcance, &sector, &mensaje)
+		t, err := time.Parse(time.RFC3339, fecha)
+		if err != nil {
+			continue
+		}
+		mensaje = strings.ToUpper(mensaje)
+		amarilla := fmt.Sprintf("%s/%d", client.TrackYellow, sector)
+		switch {
+		case categoria == "SafetyCar" && strings.Contains(mensaje, "VIRTUAL SAFETY CAR DEPLOYED"):
+			abrir(client.TrackVSC, client.TrackVSC, t)
+		case categoria == "SafetyCar" && strings.Contains(mensaje, "VIRTUAL SAFETY CAR ENDING"):
+			cerrar(t, client.TrackVSC)
+		case categoria == "SafetyCar" && strings.Contains(mensaje, "SAFETY CAR DEPLOYED"):
+			abrir(client.TrackSC, client.TrackSC, t)
+		case bandera == "RED":
+			abrir(client.TrackRed, client.TrackRed, t)
+		case bandera == "YELLOW" || bandera == "DOUBLE YELLOW":
+			abrir(amarilla, client.TrackYellow, t)
+		case bandera == "CLEAR" && sector > 0:
+			cerrar(t, amarilla)
+		case bandera == "CLEAR":
+			cerrarAmarillas(t)
+		case bandera == "GREEN" && alcance == "Track":
+			cerrar(t, client.TrackVSC, client.TrackSC, client.TrackRed)
+			cerrarAmarillas(t)
+		}
+	}
+	// Lo que no se cerro dura hasta el final de la sesion.
+	for _, p := range abiertos {
+		p.hasta = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+		periodos = append(periodos, p)
+	}
+	return periodos
+}
+
+// estadoVuelta devuelve el estado de pista mas grave durante la vuelta. Si no
+// se conoce la duracion se mira solo el momento en que empezo.
+func estadoVuelta(periodos []periodoPista, inicio string, duracion float64) string {
+	estado := client.TrackGreen
+	desde, err := time.Parse(time.RFC3339, inicio)
+	if err != nil {
+		return estado
+	}
+	hasta := desde.Add(time.Duration(duracion * float64(time.Second)))
+	for _, p := range periodos {
+		if !p.desde.After(hasta) && p.hasta.After(desde) && gravedadPista[p.estado] > gravedadPista[estado] {
+			estado = p.estado
+		}
+	}
+	return estado
+}
+
+func neutralizada(estado string) bool {
+	return estado == client.TrackVSC || estado == client.TrackSC || estado == client.TrackRed
+}
+
+// consultarEstrategias arma los stints y paradas de cada piloto, en el orden
+// de la clasificacion.
+func consultarEstrategias(sessionKey int) []client.DriverStrategy {
+	estrategias := []client.DriverStrategy{}
+	indice := map[int]int{}
+	rows, _ := db.Query(`
+		SELECT s.driver_number, d.first_name || ' ' || d.last_name, s.stint_number, s.compound, s.lap_start, s.lap_end, s.tyre_age_at_start
+		FROM stints s
+		JOIN drivers d ON d.driver_number = s.driver_number
+		LEFT JOIN positions p ON p.driver_number = s.driver_number AND p.session_key = s.session_key
+		WHERE s.session_key = ?
+		ORDER BY p.position IS NULL, p.position, s.driver_number, s.stint_number
+	`, sessionKey)
+	if rows == nil {
+		return estrategias
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var numero int
+		var piloto string
+		var st client.TyreStint
+		rows.Scan(&numero, &piloto, &st.StintNumber, &st.Compound, &st.LapStart, &st.LapEnd, &st.TyreAgeAtStart)
+		i, ok := indice[numero]
+		if !ok {
+			i = len(estrategias)
+			indice[numero] = i
+			estrategias = append(estrategias, client.DriverStrategy{DriverNumber: numero, Driver: piloto, Stints: []client.TyreStint{}, PitStops: []client.PitStop{}})
+		}
+		estrategias[i].Stints = append(estrategias[i].Stints, st)
+	}
+
+	paradas, _ := db.Query(`
+		SELECT driver_number, lap_number, pit_duration
+		FROM pit_stops
+		WHERE session_key = ?
+		ORDER BY driver_number, lap_number
+	`, sessionKey)
+	if paradas == nil {
+		return estrategias
+	}
+	defer paradas.Close()
+	for paradas.Next() {
+		var numero int
+		var ps client.PitStop
+		var duracion sql.NullFloat64
+		paradas.Scan(&numero, &ps.LapNumber, &duracion)
+		if duracion.Valid {
+			ps.PitDuration = &duracion.Float64
+		}
+		// Las paradas de un piloto sin stints cargados no tienen donde ir.
+		if i, ok := indice[numero]; ok {
+			estrategias[i].PitStops = append(estrategias[i].PitStops, ps)
+		}
+	}
+	return estrategias
+}
+
+func getResumenTemporada(c *gin.Context) {
+	r := consultarResumenTemporada(2024, c.Query("include_ties") == "true")
+	v1 := func(stats []client.SeasonStat) []StatV1 {
+		lista := []StatV1{}
+		for _, s := range stats {
+			lista = append(lista, StatV1(s))
+		}
+		return lista
+	}
+	c.JSON(200, SeasonSummaryV1{
+		Season:            r.Season,
+		Top3Winners:       v1(r.Winners),
+		Top3FastestLaps:   v1(r.FastestLaps),
+		Top3PolePositions: v1(r.PolePositions),
+	})
+}
+
+func getSeasonSummaryV2(c *gin.Context) {
+	year, ok := leerEntero(c, "year")
+	if !ok {
+		return
+	}
+	c.JSON(200, consultarResumenTemporada(year, c.Query("include_ties") == "true"))
+}
+
+// puesto es un piloto con su valor en un ranking.
+type puesto struct {
+	numero   int
+	valor    float64
+	posicion int
+}
+
+// rankear ordena de mayor a menor valor. Los empatados comparten la posicion
+// (1, 2, 2, 4) y entre ellos el orden sale del countback: mas victorias, si
+// siguen iguales mas segundos puestos, y asi; por ultimo el numero de piloto.
+// Se devuelven los primeros n o, con empates, tambien los que comparten la
+// posicion del ultimo.
+func rankear(puestos []puesto, countback map[int][]int, n int, empates bool) []puesto {
+	sort.Slice(puestos, func(i, j int) bool {
+		if puestos[i].valor != puestos[j].valor {
+			return puestos[i].valor > puestos[j].valor
+		}
+		if c := compararCountback(countback[puestos[i].numero], countback[puestos[j].numero]); c != 0 {
+			return c > 0
+		}
+		return puestos[i].numero < puestos[j].numero
+	})
+	for i := range puestos {
+		puestos[i].posicion = i + 1
+		if i > 0 && puestos[i].valor == puestos[i-1].valor {
+			puestos[i].posicion = puestos[i-1].posicion
+		}
+	}
+	corte := n
+	if corte > len(puestos) {
+		corte = len(puestos)
+	}
+	for empates && corte > 0 && corte < len(puestos) && puestos[corte].posicion == puestos[corte-1].posicion {
+		corte++
+	}
+	return puestos[:corte]
+}
+
+// posicionesFinales es la posicion final de cada piloto en cada sesion. Sale
+// de results, sin los descalificados; las sesiones que todavia no tienen
+// filas en results usan la posicion cruda de positions.
+const posicionesFinales = `
+	SELECT r.session_key, r.driver_number, r.position
+	FROM results r
+	WHERE r.status != 'DSQ'
+	UNION ALL
+	SELECT p.session_key, p.driver_number, p.position
+	FROM positions p
+	WHERE p.position > 0 AND NOT EXISTS (SELECT 1 FROM results r WHERE r.session_key = p.session_key)`
+
+// consultarCountback cuenta cuantas veces termino cada piloto en cada
+// posicion en las carreras del año (0 para todas las temporadas). El indice
+// 0 son las victorias, el 1 los segundos puestos, etc.
+func consultarCountback(year int) map[int][]int {
+	countback := map[int][]int{}
+	rows, err := db.Query(`
+		SELECT p.driver_number, p.position, COUNT(*)
+		FROM (`+posicionesFinales+`) p
+		JOIN sessions s ON s.session_key = p.session_key
+		WHERE s.session_name = 'Race' AND (?1 = 0 OR s.year = ?1)
+		GROUP BY p.driver_number, p.position
+	`, year)
+	if err != nil {
+		log.Println("Error countback:", err)
+		return countback
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var numero, posicion, veces int
+		rows.Scan(&numero, &posicion, &veces)
+		for len(countback[numero]) < posicion {
+			countback[numero] = append(countback[numero], 0)
+		}
+		countback[numero][posicion-1] = veces
+	}
+	return countback
+}
+
+// compararCountback es positivo si a tiene mejor countback que b.
+func compararCountback(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var va, vb int
+		if i < len(a) {
+			va = a[i]
+		}
+		if i < len(b) {
+			vb = b[i]
+		}
+		if va != vb {
+			return va - vb
+		}
+	}
+	return 0
+}
+
+func consultarResumenTemporada(year int, empates bool) client.SeasonSummary {
+	countback := consultarCountback(year)
+	top := func(pilotos map[int]client.SeasonStat) []client.SeasonStat {
+		stats := []client.SeasonStat{}
+		var puestos []puesto
+		for numero, s := range pilotos {
+			puestos = append(puestos, puesto{numero: numero, valor: float64(s.Value)})
+		}
+		for _, p := range rankear(puestos, countback, 3, empates) {
+			s := pilotos[p.numero]
+			s.Position = p.posicion
+			stats = append(stats, s)
+		}
+		return stats
+	}
+	getTop := func(query string, args ...interface{}) []client.SeasonStat {
+		rows, err := db.Query(query, append([]interface{}{year}, args...)...)
+		if err != nil {
+			log.Println("Error resumen de temporada:", err)
+			return []client.SeasonStat{}
+		}
+		defer rows.Close()
+		pilotos := map[int]client.SeasonStat{}
+		for rows.Next() {
+			var numero int
+			var s client.SeasonStat
+			rows.Scan(&numero, &s.Driver, &s.TeamName, &s.CountryCode, &s.Value)
+			pilotos[numero] = s
+		}
+		return top(pilotos)
+	}
+
+	victorias := getTop(`
+		SELECT d.driver_number, d.first_name || ' ' || d.last_name, d.team_name, d.country_code, COUNT(*)
+		FROM (`+posicionesFinales+`) p
+		JOIN drivers d ON p.driver_number = d.driver_number
+		JOIN sessions s ON s.session_key = p.session_key
+		WHERE p.position = 1 AND s.year = ? AND s.session_name = 'Race'
+		GROUP BY d.driver_number`)
+	// Las vueltas rapidas se cuentan carrera por carrera (ver
+	// contarVueltasRapidas).
+	vueltasRapidas := []client.SeasonStat{}
+	if conteo, err := contarVueltasRapidas(year); err != nil {
+		log.Println("Error resumen de temporada:", err)
+	} else {
+		pilotos := map[int]client.SeasonStat{}
+		for numero, n := range conteo {
+			s := client.SeasonStat{Value: n}
+			if db.QueryRow("SELECT first_name || ' ' || last_name, team_name, country_code FROM drivers WHERE driver_number = ?", numero).Scan(&s.Driver, &s.TeamName, &s.CountryCode) == nil {
+				pilotos[numero] = s
+			}
+		}
+		vueltasRapidas = top(pilotos)
+	}
+	poles := getTop(`
+		SELECT d.driver_number, d.first_name || ' ' || d.last_name, d.team_name, d.country_code, COUNT(*) 
+		FROM positions p 
+		JOIN drivers d ON p.driver_number = d.driver_number 
+		JOIN sessions s ON s.session_key = p.session_key
+		WHERE p.position = 1 AND s.year = ? AND s.session_name = 'Qualifying'
+		GROUP BY p.driver_number`)
+	victoriasSprint := getTop(`
+		SELECT d.driver_number, d.first_name || ' ' || d.last_name, d.team_name, d.country_code, COUNT(*)
+		FROM (`+posicionesFinales+`) p
+		JOIN drivers d ON p.driver_number = d.driver_number
+		JOIN sessions s ON s.session_key = p.session_key
+		WHERE p.position = 1 AND s.year = ? AND s.session_name = 'Sprint'
+		GROUP BY d.driver_number`)
+	// Los puntos salen de results, que se llena al terminar la carga.
+	puntos := func(sesion string) []client.SeasonStat {
+		return getTop(`
+		SELECT d.driver_number, d.first_name || ' ' || d.last_name, d.team_name, d.country_code, SUM(r.points)
+		FROM results r
+		JOIN drivers d ON r.driver_number = d.driver_number
+		JOIN sessions s ON s.session_key = r.session_key
+		WHERE s.year = ? AND s.session_name = ?
+		GROUP BY d.driver_number`, sesion)
+	}
+	return client.SeasonSummary{
+		Season:        year,
+		Winners:       victorias,
+		FastestLaps:   vueltasRapidas,
+		PolePositions: poles,
+		Points:        puntos(client.SessionRace),
+		SprintWinners: victoriasSprint,
+		SprintPoints:  puntos(client.SessionSprint),
+	}
+}
+
+// metrica es una estadistica del ranking de /estadisticas. La consulta
+// devuelve driver_number y valor por piloto; ?1 es el año, o 0 para contar
+// todas las temporadas cargadas. Si el valor no sale de una consulta, contar
+// lo calcula por piloto en su lugar. Para agregar una estadistica basta con
+// sumarla a metricas.
+type metrica struct {
+	client.Metric
+	consulta string
+	contar   func(year int) (map[int]int, error)
+}
+
+var metricas = []metrica{
+	{Metric: client.Metric{Name: "wins", Description: "Carreras ganadas", Unit: "carreras"}, consulta: `
+		SELECT p.driver_number, COUNT(*) AS valor
+		FROM (`+posicionesFinales+`) p
+		JOIN sessions s ON s.session_key = p.session_key
+		WHERE p.position = 1 AND s.session_name = 'Race' AND (?1 = 0 OR s.year = ?1)
+		GROUP BY p.driver_number`},
+	{Metric: client.Metric{Name: "podiums", Description: "Podios en carrera", Unit: "carreras"}, consulta: `
+		SELECT p.driver_number, COUNT(*) AS valor
+		FROM (`+posicionesFinales+`) p
+		JOIN sessions s ON s.session_key = p.session_key
+		WHERE p.position <= 3 AND s.session_name = 'Race' AND (?1 = 0 OR s.year = ?1)
+		GROUP BY p.driver_number`},
+	{Metric: client.Metric{Name: "poles", Description: "Pole positions", Unit: "clasificaciones"}, consulta: `
+		SELECT p.driver_number, COUNT(*) AS valor
+		FROM positions p
+		JOIN sessions s ON s.session_key = p.session_key
+		WHERE p.position = 1 AND s.session_name = 'Qualifying' AND (?1 = 0 OR s.year = ?1)
+		GROUP BY p.driver_number`},
+	{Metric: client.Metric{Name: "fastest_laps", Description: "Vueltas rápidas en carrera", Unit: "carreras"}, contar: contarVueltasRapidas},
+	{Metric: client.Metric{Name: "top_speed", Description: "Velocidad máxima en la trampa de velocidad en carrera", Unit: "km/h"}, consulta: `
+		SELECT l.driver_number, MAX(l.st_speed) AS valor
+		FROM laps l
+		JOIN sessions s ON s.session_key = l.session_key
+		WHERE s.session_type = 'Race' AND (?1 = 0 OR s.year = ?1)
+		GROUP BY l.driver_number`},
+	{Metric: client.Metric{Name: "laps_led", Description: "Vueltas lideradas en carrera", Unit: "vueltas"}, consulta: `
+		SELECT ll.driver_number, COUNT(*) AS valor
+		FROM lap_leaders ll
+		JOIN sessions s ON s.session_key = ll.session_key
+		WHERE s.session_name = 'Race' AND (?1 = 0 OR s.year = ?1)
+		GROUP BY ll.driver_number`},
+	{Metric: client.Metric{Name: "points", Description: "Puntos de carreras y sprints", Unit: "puntos"}, consulta: `
+		SELECT r.driver_number, SUM(r.points) AS valor
+		FROM results r
+		JOIN sessions s ON s.session_key = r.session_key
+		WHERE s.session_type = 'Race' AND (?1 = 0 OR s.year = ?1)
+		GROUP BY r.driver_number`},
+}
+
+// limitEstadisticas es la cantidad de pilotos del ranking si no se pide otra.
+const limitEstadisticas = 10
+
+func buscarMetrica(nombre string) (metrica, bool) {
+	for _, m := range metricas {
+		if m.Name == nombre {
+			return m, true
+		}
+	}
+	return metrica{}, false
+}
+
+func getMetricas(c *gin.Context) {
+	lista := client.MetricList{Metrics: []client.Metric{}}
+	for _, m := range metricas {
+		lista.Metrics = append(lista.Metrics, m.Metric)
+	}
+	c.JSON(200, lista)
+}
+
+func getEstadisticas(c *gin.Context) {
+	m, ok := buscarMetrica(c.DefaultQuery("metric", "wins"))
+	if !ok {
+		var nombres []string
+		for _, m := range metricas {
+			nombres = append(nombres, m.Name)
+		}
+		c.JSON(400, gin.H{"error": "metric debe ser una de: " + strings.Join(nombres, ", ")})
+		return
+	}
+	scope := c.DefaultQuery("scope", client.ScopeSeason)
+	if scope != client.ScopeSeason && scope != client.ScopeCareer {
+		c.JSON(400, gin.H{"error": "scope debe ser season o career"})
+		return
+	}
+	year, err := strconv.Atoi(c.DefaultQuery("year", "2024"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "year debe ser un número"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(limitEstadisticas)))
+	if err != nil || limit < 1 || limit > limitMaximo {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("limit debe estar entre 1 y %d", limitMaximo)})
+		return
+	}
+	if scope == client.ScopeCareer {
+		year = 0
+	}
+	ranking, err := consultarEstadisticas(m, year, limit, c.Query("include_ties") == "true")
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, ranking)
+}
+
+// consultarEstadisticas arma el ranking de una metrica; year 0 cuenta todas
+// las temporadas. Los pilotos con valor 0 no aparecen. Los empates se
+// resuelven como en el resumen de temporada (ver rankear).
+func consultarEstadisticas(m metrica, year, limit int, empates bool) (client.Leaderboard, error) {
+	ranking := client.Leaderboard{Metric: m.Metric, Scope: client.ScopeCareer, Entries: []client.LeaderboardEntry{}}
+	if year != 0 {
+		ranking.Scope, ranking.Year = client.ScopeSeason, &year
+	}
+	pilotos := map[int]client.LeaderboardEntry{}
+	var puestos []puesto
+	if m.contar != nil {
+		conteo, err := m.contar(year)
+		if err != nil {
+			return ranking, err
+		}
+		for numero, n := range conteo {
+			e := client.LeaderboardEntry{DriverNumber: numero, Value: float64(n)}
+			if n == 0 || db.QueryRow("SELECT first_name || ' ' || last_name, team_name, country_code FROM drivers WHERE driver_number = ?", numero).Scan(&e.Driver, &e.TeamName, &e.CountryCode) != nil {
+				continue
+			}
+			pilotos[numero] = e
+			puestos = append(puestos, puesto{numero: numero, valor: e.Value})
+		}
+	} else {
+		rows, err := db.Query(`
+			SELECT d.driver_number, d.first_name || ' ' || d.last_name, d.team_name, d.country_code, m.valor
+			FROM (`+m.consulta+`) m
+			JOIN drivers d ON d.driver_number = m.driver_number
+			WHERE m.valor > 0
+		`, year)
+		if err != nil {
+			return ranking, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e client.LeaderboardEntry
+			rows.Scan(&e.DriverNumber, &e.Driver, &e.TeamName, &e.CountryCode, &e.Value)
+			pilotos[e.DriverNumber] = e
+			puestos = append(puestos, puesto{numero: e.DriverNumber, valor: e.Value})
+		}
+		if err := rows.Err(); err != nil {
+			return ranking, err
+		}
+	}
+	for _, p := range rankear(puestos, consultarCountback(year), limit, empates) {
+		e := pilotos[p.numero]
+		e.Position = p.posicion
+		ranking.Entries = append(ranking.Entries, e)
+	}
+	return ranking, nil
+}
+
+func getFiabilidad(c *gin.Context) {
+	year, err := strconv.Atoi(c.DefaultQuery("year", "2024"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "year debe ser un número"})
+		return
+	}
+	c.JSON(200, consultarFiabilidad(year))
+}
+
+// consultarFiabilidad cuenta por equipo las largadas (sin DNS ni sprints),
+// abandonos y descalificaciones guardados en results. El equipo es el de
+// cada carrera (driver_sessions), asi un cambio de equipo no pasa los
+// abandonos al equipo nuevo. La fiabilidad es la fraccion de largadas que no
+// termino en abandono; una descalificacion no cuenta como falla del auto.
+func consultarFiabilidad(year int) client.TeamReliabilityReport {
+	reporte := client.TeamReliabilityReport{Year: year, Teams: []client.TeamReliability{}}
+	rows, err := db.Query(`
+		SELECT COALESCE(ds.team_name, d.team_name) AS equipo,
+		       COUNT(*),
+		       SUM(CASE WHEN r.status = ? THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN r.status = ? THEN 1 ELSE 0 END)
+		FROM results r
+		JOIN drivers d ON d.driver_number = r.driver_number
+		JOIN sessions s ON s.session_key = r.session_key
+		LEFT JOIN driver_sessions ds ON ds.session_key = r.session_key AND ds.driver_number = r.driver_number
+		WHERE s.year = ? AND s.session_name = 'Race' AND r.status != ?
+		GROUP BY equipo
+	`, client.StatusDNF, client.StatusDSQ, year, client.StatusDNS)
+	if err != nil {
+		log.Println("Error fiabilidad:", err)
+		return reporte
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t client.TeamReliability
+		rows.Scan(&t.TeamName, &t.Starts, &t.DNFs, &t.DSQs)
+		t.Finishes = t.Starts - t.DNFs - t.DSQs
+		t.ReliabilityRate = float64(t.Starts-t.DNFs) / float64(t.Starts)
+		reporte.Teams = append(reporte.Teams, t)
+	}
+	sort.SliceStable(reporte.Teams, func(i, j int) bool {
+		if reporte.Teams[i].ReliabilityRate != reporte.Teams[j].ReliabilityRate {
+			return reporte.Teams[i].ReliabilityRate > reporte.Teams[j].ReliabilityRate
+		}
+		return reporte.Teams[i].TeamName < reporte.Teams[j].TeamName
+	})
+	return reporte
+}
+
+func getCompaneros(c *gin.Context) {
+	responderCompaneros(c, c.Param("id"))
+}
+
+func getTeammatesV2(c *gin.Context) {
+	responderCompaneros(c, c.Param("team_id"))
+}
+
+func responderCompaneros(c *gin.Context, equipo string) {
+	year, err := strconv.Atoi(c.DefaultQuery("year", "2024"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "year debe ser un número"})
+		return
+	}
+	companeros, err := consultarCompaneros(equipo, year)
+	if err == sql.ErrNoRows {
+		c.JSON(404, gin.H{"error": "no existe el equipo"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, companeros)
+}
+
+// slugEquipo pasa un nombre de equipo a minusculas con guiones para usarlo en
+// la URL: "Red Bull Racing" queda "red-bull-racing".
+func slugEquipo(nombre string) string {
+	var b strings.Builder
+	guion := false
+	for _, r := range strings.ToLower(nombre) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if guion && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			guion = false
+		} else {
+			guion = true
+		}
+	}
+	return b.String()
+}
+
+// buscarEquipo devuelve el nombre de equipo que corresponde a id, que puede
+// ser el nombre tal cual o su slug.
+func buscarEquipo(id string) (string, error) {
+	rows, err := db.Query("SELECT team_name FROM driver_sessions UNION SELECT team_name FROM drivers")
+	if err != nil {
+		// Bases creadas antes de la tabla driver_sessions.
+		rows, err = db.Query("SELECT DISTINCT team_name FROM drivers")
+		if err != nil {
+			return "", err
+		}
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var nombre sql.NullString
+		rows.Scan(&nombre)
+		if nombre.Valid && (strings.EqualFold(nombre.String, id) || slugEquipo(nombre.String) == slugEquipo(id)) {
+			return nombre.String, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
+
+// equiposPorSesion devuelve el equipo de cada piloto en una sesion. Si la
+// sesion no tiene equipos cargados se usa el equipo actual de drivers.
+func equiposPorSesion(sessionKey int) map[int]string {
+	equipos := map[int]string{}
+	rows, err := db.Query("SELECT driver_number, team_name FROM driver_sessions WHERE session_key = ?", sessionKey)
+	if err == nil {
+		for rows.Next() {
+			var numero int
+			var equipo string
+			rows.Scan(&numero, &equipo)
+			equipos[numero] = equipo
+		}
+		rows.Close()
+	}
+	if len(equipos) > 0 {
+		return equipos
+	}
+	rows, err = db.Query("SELECT driver_number, team_name FROM drivers")
+	if err != nil {
+		return equipos
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var numero int
+		var equipo string
+		rows.Scan(&numero, &equipo)
+		equipos[numero] = equipo
+	}
+	return equipos
+}
+
+// mejoresVueltas devuelve la mejor vuelta valida (con los tres sectores) de
+// cada piloto en una sesion.
+func mejoresVueltas(sessionKey int) map[int]float64 {
+	mejores := map[int]float64{}
+	rows, err := db.Query(`
+		SELECT driver_number, MIN(lap_duration)
+		FROM laps
+		WHERE session_key = ?
+		  AND lap_duration > 0
+		  AND duration_sector_1 > 0
+		  AND duration_sector_2 > 0
+		  AND duration_sector_3 > 0
+		GROUP BY driver_number
+	`, sessionKey)
+	if err != nil {
+		return mejores
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var numero int
+		var vuelta float64
+		rows.Scan(&numero, &vuelta)
+		mejores[numero] = vuelta
+	}
+	return mejores
+}
+
+// consultarCompaneros recorre las clasificaciones, sprints y carreras del año
+// y compara a los pilotos que corrieron juntos para el equipo en cada sesion,
+// segun el equipo que tenia cada uno en esa sesion. Los puntos de una pareja
+// son solo los de las sesiones que corrieron juntos; los del piloto son todos
+// los que sumo para el equipo.
+func consultarCompaneros(id string, year int) (client.TeamTeammates, error) {
+	resultado := client.TeamTeammates{Year: year, Drivers: []client.TeamDriverPoints{}, Pairs: []client.TeammatePair{}}
+	equipo, err := buscarEquipo(id)
+	if err != nil {
+		return resultado, err
+	}
+	resultado.TeamName = equipo
+
+	nombres := map[int]string{}
+	rows, err := db.Query("SELECT driver_number, first_name || ' ' || last_name FROM drivers")
+	if err != nil {
+		return resultado, err
+	}
+	for rows.Next() {
+		var numero int
+		var nombre string
+		rows.Scan(&numero, &nombre)
+		nombres[numero] = nombre
+	}
+	rows.Close()
+
+	type sesion struct {
+		key    int
+		nombre string
+	}
+	var sesiones []sesion
+	rows, err = db.Query(`
+		SELECT session_key, session_name
+		FROM sessions
+		WHERE year = ? AND session_name IN ('Qualifying', 'Sprint', 'Race')
+		ORDER BY date_start
+	`, year)
+	if err != nil {
+		return resultado, err
+	}
+	for rows.Next() {
+		var s sesion
+		rows.Scan(&s.key, &s.nombre)
+		sesiones = append(sesiones, s)
+	}
+	rows.Close()
+
+	type pareja struct {
+		client.TeammatePair
+		sumaQualy, sumaVuelta float64
+		nQualy, nVuelta       int
+	}
+	pilotos := map[int]*client.TeamDriverPoints{}
+	parejas := map[[2]int]*pareja{}
+	obtenerPareja := func(a, b int) *pareja {
+		k := [2]int{a, b}
+		if parejas[k] == nil {
+			parejas[k] = &pareja{TeammatePair: client.TeammatePair{
+				DriverA: client.HeadToHeadDriver{DriverNumber: a, Driver: nombres[a], TeamName: equipo},
+				DriverB: client.HeadToHeadDriver{DriverNumber: b, Driver: nombres[b], TeamName: equipo},
+			}}
+		}
+		return parejas[k]
+	}
+
+	for _, s := range sesiones {
+		var delEquipo []int
+		for numero, e := range equiposPorSesion(s.key) {
+			if _, ok := nombres[numero]; ok && e == equipo {
+				delEquipo = append(delEquipo, numero)
+			}
+		}
+		if len(delEquipo) == 0 {
+			continue
+		}
+		sort.Ints(delEquipo)
+		vueltas := mejoresVueltas(s.key)
+
+		// Posicion de cada piloto del equipo que tomo parte de la sesion.
+		posiciones := map[int]int{}
+		puntos := map[int]int{}
+		if s.nombre == "Qualifying" {
+			for _, numero := range delEquipo {
+				var pos int
+				if db.QueryRow("SELECT position FROM positions WHERE session_key = ? AND driver_number = ?", s.key, numero).Scan(&pos) == nil && pos > 0 {
+					posiciones[numero] = pos
+				}
+			}
+		} else {
+			for _, r := range consultarClasificacion(s.key) {
+				if r.Status != client.StatusDNS && contains(delEquipo, r.DriverNumber) {
+					posiciones[r.DriverNumber] = r.Position
+					puntos[r.DriverNumber] = r.Points
+				}
+			}
+		}
+
+		for numero := range posiciones {
+			if pilotos[numero] == nil {
+				pilotos[numero] = &client.TeamDriverPoints{DriverNumber: numero, Driver: nombres[numero]}
+			}
+			pilotos[numero].Points += puntos[numero]
+			if s.nombre == client.SessionRace {
+				pilotos[numero].Races++
+			}
+		}
+
+		for i, a := range delEquipo {
+			for _, b := range delEquipo[i+1:] {
+				posA, okA := posiciones[a]
+				posB, okB := posiciones[b]
+				if !okA || !okB {
+					continue
+				}
+				p := obtenerPareja(a, b)
+				p.PointsA += puntos[a]
+				p.PointsB += puntos[b]
+				h2h := &p.RaceHeadToHead
+				switch s.nombre {
+				case "Qualifying":
+					h2h = &p.QualifyingHeadToHead
+				case client.SessionSprint:
+					// Los sprints solo suman puntos.
+					continue
+				default:
+					p.RacesTogether++
+				}
+				// Sin posicion o con la misma posicion no gana ninguno.
+				switch {
+				case posA <= 0 || posB <= 0 || posA == posB:
+				case posA < posB:
+					h2h.A++
+				default:
+					h2h.B++
+				}
+				vueltaA, okA := vueltas[a]
+				vueltaB, okB := vueltas[b]
+				if !okA || !okB {
+					continue
+				}
+				if s.nombre == "Qualifying" {
+					p.sumaQualy += vueltaA - vueltaB
+					p.nQualy++
+				} else {
+					p.sumaVuelta += vueltaA - vueltaB
+					p.nVuelta++
+				}
+			}
+		}
+	}
+
+	for _, p := range pilotos {
+		resultado.Points += p.Points
+		resultado.Drivers = append(resultado.Drivers, *p)
+	}
+	for i := range resultado.Drivers {
+		if resultado.Points > 0 {
+			resultado.Drivers[i].Share = float64(resultado.Drivers[i].Points) / float64(resultado.Points)
+		}
+	}
+	sort.Slice(resultado.Drivers, func(i, j int) bool {
+		if resultado.Drivers[i].Points != resultado.Drivers[j].Points {
+			return resultado.Drivers[i].Points > resultado.Drivers[j].Points
+		}
+		return resultado.Drivers[i].DriverNumber < resultado.Drivers[j].DriverNumber
+	})
+
+	for _, p := range parejas {
+		if p.nQualy > 0 {
+			prom := p.sumaQualy / float64(p.nQualy)
+			p.AvgQualifyingGap = &prom
+		}
+		if p.nVuelta > 0 {
+			prom := p.sumaVuelta / float64(p.nVuelta)
+			p.AvgRaceBestLapGap = &prom
+		}
+		resultado.Pairs = append(resultado.Pairs, p.TeammatePair)
+	}
+	sort.Slice(resultado.Pairs, func(i, j int) bool {
+		if resultado.Pairs[i].DriverA.DriverNumber != resultado.Pairs[j].DriverA.DriverNumber {
+			return resultado.Pairs[i].DriverA.DriverNumber < resultado.Pairs[j].DriverA.DriverNumber
+		}
+		return resultado.Pairs[i].DriverB.DriverNumber < resultado.Pairs[j].DriverB.DriverNumber
+	})
+	return resultado, nil
+}
+
+func getVueltas(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "session_key")
+	if !ok {
+		return
+	}
+	driverNumber, ok := leerEntero(c, "driver_number")
+	if !ok {
+		return
+	}
+	soloValidas, err := strconv.ParseBool(c.DefaultQuery("valid_only", "false"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "valid_only debe ser true o false"})
+		return
+	}
+	c.JSON(200, consultarVueltas(sessionKey, driverNumber, soloValidas))
+}
+
+func consultarVueltas(sessionKey, driverNumber int, soloValidas bool) client.DriverLaps {
+	resultado := client.DriverLaps{SessionKey: sessionKey, DriverNumber: driverNumber, Laps: []client.Lap{}}
+
+	var mejorSesion sql.NullFloat64
+	db.QueryRow(`
+		SELECT MIN(lap_duration)
+		FROM laps
+		WHERE session_key = ?
+		  AND lap_duration > 0
+		  AND duration_sector_1 > 0
+		  AND duration_sector_2 > 0
+		  AND duration_sector_3 > 0
+	`, sessionKey).Scan(&mejorSesion)
+	if mejorSesion.Valid {
+		resultado.SessionBest = &mejorSesion.Float64
+	}
+
+	periodos := consultarPeriodosPista(sessionKey)
+	rows, _ := db.Query(`
+		SELECT lap_number, date_start, lap_duration, duration_sector_1, duration_sector_2, duration_sector_3, st_speed
+		FROM laps
+		WHERE session_key = ? AND driver_number = ?
+		ORDER BY lap_number
+	`, sessionKey, driverNumber)
+	defer rows.Close()
+	for rows.Next() {
+		var l client.Lap
+		var fecha sql.NullString
+		rows.Scan(&l.LapNumber, &fecha, &l.LapDuration, &l.Sector1, &l.Sector2, &l.Sector3, &l.StSpeed)
+		l.DateStart = fecha.String
+		l.Valid = l.LapDuration > 0 && l.Sector1 > 0 && l.Sector2 > 0 && l.Sector3 > 0
+		l.TrackStatus = estadoVuelta(periodos, l.DateStart, l.LapDuration)
+		l.Neutralised = neutralizada(l.TrackStatus)
+		if soloValidas && !l.Valid {
+			continue
+		}
+		if l.Valid && (resultado.PersonalBest == nil || l.LapDuration < *resultado.PersonalBest) {
+			mejor := l.LapDuration
+			resultado.PersonalBest = &mejor
+		}
+		resultado.Laps = append(resultado.Laps, l)
+	}
+
+	// Las deltas se calculan al final, cuando ya se conoce la mejor vuelta.
+	for i := range resultado.Laps {
+		l := &resultado.Laps[i]
+		if !l.Valid {
+			continue
+		}
+		personal := l.LapDuration - *resultado.PersonalBest
+		l.DeltaToPersonalBest = &personal
+		if resultado.SessionBest != nil {
+			sesion := l.LapDuration - *resultado.SessionBest
+			l.DeltaToSessionBest = &sesion
+		}
+	}
+	return resultado
+}
+
+// consultarVueltaIdeal suma los mejores sectores de cada piloto. Los sectores
+// se toman por separado, asi que cuenta tambien los de vueltas incompletas.
+func consultarVueltaIdeal(sessionKey int) client.IdealLap {
+	rows, _ := db.Query(`
+		SELECT l.driver_number, d.first_name || ' ' || d.last_name,
+		       MIN(CASE WHEN l.duration_sector_1 > 0 THEN l.duration_sector_1 END),
+		       MIN(CASE WHEN l.duration_sector_2 > 0 THEN l.duration_sector_2 END),
+		       MIN(CASE WHEN l.duration_sector_3 > 0 THEN l.duration_sector_3 END),
+		       MIN(CASE WHEN l.lap_duration > 0 AND l.duration_sector_1 > 0 AND l.duration_sector_2 > 0 AND l.duration_sector_3 > 0
+		                THEN l.lap_duration END)
+		FROM laps l
+		JOIN drivers d ON d.driver_number = l.driver_number
+		WHERE l.session_key = ?
+		GROUP BY l.driver_number
+	`, sessionKey)
+	defer rows.Close()
+
+	ideal := client.IdealLap{Drivers: []client.DriverIdealLap{}}
+	morados := []*client.PurpleSector{&ideal.Sector1, &ideal.Sector2, &ideal.Sector3}
+	for rows.Next() {
+		var v client.DriverIdealLap
+		var s1, s2, s3, mejor sql.NullFloat64
+		rows.Scan(&v.DriverNumber, &v.Driver, &s1, &s2, &s3, &mejor)
+		// Los sectores morados cuentan aunque al piloto le falte otro sector;
+		// sin los tres no tiene vuelta ideal propia.
+		for i, tiempo := range []sql.NullFloat64{s1, s2, s3} {
+			if tiempo.Valid && (morados[i].Duration == 0 || tiempo.Float64 < morados[i].Duration) {
+				*morados[i] = client.PurpleSector{DriverNumber: v.DriverNumber, Driver: v.Driver, Duration: tiempo.Float64}
+			}
+		}
+		if !s1.Valid || !s2.Valid || !s3.Valid {
+			continue
+		}
+		v.Sector1, v.Sector2, v.Sector3, v.BestLap = s1.Float64, s2.Float64, s3.Float64, mejor.Float64
+		v.IdealLap = v.Sector1 + v.Sector2 + v.Sector3
+		ideal.Drivers = append(ideal.Drivers, v)
+	}
+	ideal.LapDuration = ideal.Sector1.Duration + ideal.Sector2.Duration + ideal.Sector3.Duration
+	sort.Slice(ideal.Drivers, func(i, j int) bool {
+		return ideal.Drivers[i].IdealLap < ideal.Drivers[j].IdealLap
+	})
+	return ideal
+}
+
+func getDiferencias(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "id")
+	if !ok {
+		return
+	}
+	responderDiferencias(c, sessionKey)
+}
+
+func getRaceGapsV2(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "session_key")
+	if !ok {
+		return
+	}
+	responderDiferencias(c, sessionKey)
+}
+
+func responderDiferencias(c *gin.Context, sessionKey int) {
+	pilotos, err := listaEnteros(c.Query("drivers"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "drivers debe ser una lista de números separados por coma"})
+		return
+	}
+	c.JSON(200, consultarDiferencias(sessionKey, pilotos))
+}
+
+type muestraIntervalo struct {
+	fecha        time.Time
+	texto        string
+	gap          sql.NullFloat64
+	intervalo    sql.NullFloat64
+	vueltasAtras int
+}
+
+// consultarDiferencias toma, para cada vuelta de cada piloto, la ultima
+// medicion de intervals antes de que termine la vuelta. OpenF1 manda una
+// medicion cada pocos segundos, asi que queda una por vuelta para graficar.
+func consultarDiferencias(sessionKey int, pilotos []int) client.RaceGaps {
+	resultado := client.RaceGaps{SessionKey: sessionKey, Drivers: []client.DriverGaps{}}
+
+	muestras := map[int][]muestraIntervalo{}
+	rows, err := db.Query(`
+		SELECT driver_number, date, gap_to_leader, interval, laps_behind
+		FROM intervals
+		WHERE session_key = ?
+		ORDER BY driver_number, date
+	`, sessionKey)
+	if err != nil {
+		return resultado
+	}
+	for rows.Next() {
+		var numero int
+		var m muestraIntervalo
+		rows.Scan(&numero, &m.texto, &m.gap, &m.intervalo, &m.vueltasAtras)
+		if m.fecha, err = time.Parse(time.RFC3339, m.texto); err != nil {
+			continue
+		}
+		muestras[numero] = append(muestras[numero], m)
+	}
+	rows.Close()
+
+	vueltas, _ := db.Query(`
+		SELECT l.driver_number, d.first_name || ' ' || d.last_name, l.lap_number, l.date_start, l.lap_duration
+		FROM laps l
+		JOIN drivers d ON d.driver_number = l.driver_number
+		LEFT JOIN positions p ON p.driver_number = l.driver_number AND p.session_key = l.session_key
+		WHERE l.session_key = ?
+		ORDER BY p.position IS NULL, p.position, l.driver_number, l.lap_number
+	`, sessionKey)
+	defer vueltas.Close()
+	indice := map[int]int{}
+	for vueltas.Next() {
+		var numero, vuelta int
+		var piloto string
+		var inicio sql.NullString
+		var duracion float64
+		vueltas.Scan(&numero, &piloto, &vuelta, &inicio, &duracion)
+		if len(pilotos) > 0 && !contains(pilotos, numero) {
+			continue
+		}
+		i, ok := indice[numero]
+		if !ok {
+			i = len(resultado.Drivers)
+			indice[numero] = i
+			resultado.Drivers = append(resultado.Drivers, client.DriverGaps{DriverNumber: numero, Driver: piloto, Points: []client.GapPoint{}})
+		}
+		desde, err := time.Parse(time.RFC3339, inicio.String)
+		if err != nil || duracion <= 0 {
+			continue
+		}
+		fin := desde.Add(time.Duration(duracion * float64(time.Second)))
+
+		// Ultima muestra que no sea posterior al final de la vuelta.
+		lista := muestras[numero]
+		j := sort.Search(len(lista), func(k int) bool { return lista[k].fecha.After(fin) }) - 1
+		if j < 0 || lista[j].fecha.Before(desde) {
+			continue
+		}
+		punto := client.GapPoint{LapNumber: vuelta, Date: lista[j].texto, LapsBehind: lista[j].vueltasAtras}
+		if lista[j].gap.Valid {
+			punto.GapToLeader = &lista[j].gap.Float64
+		}
+		if lista[j].intervalo.Valid {
+			punto.Interval = &lista[j].intervalo.Float64
+		}
+		resultado.Drivers[i].Points = append(resultado.Drivers[i].Points, punto)
+	}
+	return resultado
+}
+
+const (
+	puntosPorDefecto = 200
+	puntosMaximo     = 2000
+)
+
+func getTelemetria(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "session_key")
+	if !ok {
+		return
+	}
+	driverNumber, ok := leerEntero(c, "driver_number")
+	if !ok {
+		return
+	}
+	lapNumber, ok := leerEntero(c, "lap_number")
+	if !ok {
+		return
+	}
+	puntos, ok := leerPuntos(c)
+	if !ok {
+		return
+	}
+	muestras, err := consultarMuestras(sessionKey, driverNumber, lapNumber)
+	if err == sql.ErrNoRows || err == nil && len(muestras) == 0 {
+		c.JSON(404, gin.H{"error": "no hay telemetría cargada para esa vuelta"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	t := client.LapTelemetry{SessionKey: sessionKey, DriverNumber: driverNumber, LapNumber: lapNumber, Samples: len(muestras)}
+	for _, m := range muestras {
+		t.MaxSpeed = math.Max(t.MaxSpeed, float64(m.velocidad))
+	}
+	t.Points = reducirTelemetria(muestras, puntos)
+	c.JSON(200, t)
+}
+
+func leerPuntos(c *gin.Context) (int, bool) {
+	puntos, err := strconv.Atoi(c.DefaultQuery("points", strconv.Itoa(puntosPorDefecto)))
+	if err != nil || puntos < 2 || puntos > puntosMaximo {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("points debe estar entre 2 y %d", puntosMaximo)})
+		return 0, false
+	}
+	return puntos, true
+}
+
+func getComparacionVueltas(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "session_key")
+	if !ok {
+		return
+	}
+	var vueltas [2]client.LapRef
+	for i, nombre := range []string{"a", "b"} {
+		if _, err := fmt.Sscanf(c.Query(nombre), "%d:%d", &vueltas[i].DriverNumber, &vueltas[i].LapNumber); err != nil {
+			c.JSON(400, gin.H{"error": nombre + " debe tener la forma piloto:vuelta, por ejemplo 1:45"})
+			return
+		}
+	}
+	puntos, ok := leerPuntos(c)
+	if !ok {
+		return
+	}
+
+	var trazas [2][]client.TelemetryPoint
+	for i := range vueltas {
+		v := &vueltas[i]
+		muestras, err := consultarMuestras(sessionKey, v.DriverNumber, v.LapNumber)
+		// Una vuelta puede estar cargada sin muestras de car_data.
+		if err == sql.ErrNoRows || err == nil && len(muestras) == 0 {
+			c.JSON(404, gin.H{"error": fmt.Sprintf("no hay telemetría cargada para la vuelta %d del piloto %d", v.LapNumber, v.DriverNumber)})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		trazas[i] = reducirTelemetria(muestras, len(muestras))
+		db.QueryRow(`
+			SELECT d.first_name || ' ' || d.last_name, COALESCE(l.lap_duration, 0)
+			FROM drivers d
+			LEFT JOIN laps l ON l.driver_number = d.driver_number AND l.session_key = ? AND l.lap_number = ?
+			WHERE d.driver_number = ?
+		`, sessionKey, v.LapNumber, v.DriverNumber).Scan(&v.Driver, &v.LapDuration)
+	}
+	c.JSON(200, compararVueltas(sessionKey, vueltas[0], vueltas[1], trazas[0], trazas[1], puntos))
+}
+
+// compararVueltas muestrea las dos trazas en n distancias iguales. La
+// distancia de b se escala al largo de a para que las dos terminen juntas.
+// Si alguna traza esta vacia no hay puntos.
+func compararVueltas(sessionKey int, refA, refB client.LapRef, a, b []client.TelemetryPoint, n int) client.LapComparison {
+	cmp := client.LapComparison{SessionKey: sessionKey, A: refA, B: refB, Points: []client.LapComparisonPoint{}}
+	if len(a) == 0 || len(b) == 0 {
+		return cmp
+	}
+	largoA, largoB := a[len(a)-1].Distance, b[len(b)-1].Distance
+	if largoA <= 0 || largoB <= 0 {
+		return cmp
+	}
+	cmp.LapLength = largoA
+	// La distancia se empieza a contar en la primera muestra, asi que el tiempo
+	// tambien, para que el delta arranque en cero.
+	escala := largoA / largoB
+	inicioA, inicioB := a[0].Time, b[0].Time
+	for i := range a {
+		a[i].Time -= inicioA
+	}
+	for i := range b {
+		b[i].Distance *= escala
+		b[i].Time -= inicioB
+	}
+	for k := 0; k < n; k++ {
+		d := largoA * float64(k) / float64(n-1)
+		pa, pb := enDistancia(a, d), enDistancia(b, d)
+		cmp.Points = append(cmp.Points, client.LapComparisonPoint{
+			Distance:  d,
+			SpeedA:    pa.Speed,
+			SpeedB:    pb.Speed,
+			ThrottleA: pa.Throttle,
+			ThrottleB: pb.Throttle,
+			BrakeA:    pa.Brake,
+			BrakeB:    pb.Brake,
+			Delta:     pb.Time - pa.Time,
+		})
+	}
+	return cmp
+}
+
+// enDistancia interpola la traza en la distancia d. Freno y marcha se toman
+// de la muestra anterior.
+func enDistancia(traza []client.TelemetryPoint, d float64) client.TelemetryPoint {
+	j := sort.Search(len(traza), func(i int) bool { return traza[i].Distance >= d })
+	if j == 0 {
+		return traza[0]
+	}
+	if j == len(traza) {
+		return traza[len(traza)-1]
+	}
+	a, b := traza[j-1], traza[j]
+	f := 0.0
+	if b.Distance > a.Distance {
+		f = (d - a.Distance) / (b.Distance - a.Distance)
+	}
+	p := a
+	p.Distance = d
+	p.Time = a.Time + (b.Time-a.Time)*f
+	p.Speed = a.Speed + (b.Speed-a.Speed)*f
+	p.Throttle = a.Throttle + (b.Throttle-a.Throttle)*f
+	return p
+}
+
+func consultarMuestras(sessionKey, driverNumber, lapNumber int) ([]muestraTelemetria, error) {
+	// Sin la tabla (base anterior a la telemetria) no hay nada cargado.
+	if !existeTabla(db, "car_data") {
+		return nil, sql.ErrNoRows
+	}
+	var data []byte
+	err := db.QueryRow(`
+		SELECT data
+		FROM car_data
+		WHERE session_key = ? AND driver_number = ? AND lap_number = ?
+	`, sessionKey, driverNumber, lapNumber).Scan(&data)
+	if err != nil {
+		return nil, err
+	}
+	return decodificarTelemetria(data)
+}
+
+// reducirTelemetria calcula la distancia recorrida y deja n puntos a
+// intervalos de tiempo iguales. Los canales continuos se interpolan; marcha,
+// freno y DRS toman el valor de la muestra mas cercana.
+func reducirTelemetria(muestras []muestraTelemetria, n int) []client.TelemetryPoint {
+	puntos := make([]client.TelemetryPoint, len(muestras))
+	for i, m := range muestras {
+		puntos[i] = client.TelemetryPoint{
+			Time:     float64(m.ms) / 1000,
+			Speed:    float64(m.velocidad),
+			Throttle: float64(m.acelerador),
+			Brake:    int(m.freno),
+			Gear:     int(m.marcha),
+			RPM:      int(m.rpm),
+			DRS:      int(m.drs),
+		}
+		if i > 0 {
+			// km/h a m/s, con el promedio de las dos muestras.
+			dt := puntos[i].Time - puntos[i-1].Time
+			puntos[i].Distance = puntos[i-1].Distance + (puntos[i].Speed+puntos[i-1].Speed)/2/3.6*dt
+		}
+	}
+	if len(puntos) <= n {
+		return puntos
+	}
+
+	reducidos := make([]client.TelemetryPoint, n)
+	total := puntos[len(puntos)-1].Time
+	j := 0
+	for k := range reducidos {
+		t := total * float64(k) / float64(n-1)
+		for j < len(puntos)-2 && puntos[j+1].Time < t {
+			j++
+		}
+		a, b := puntos[j], puntos[j+1]
+		f := 0.0
+		if b.Time > a.Time {
+			f = math.Min(1, math.Max(0, (t-a.Time)/(b.Time-a.Time)))
+		}
+		cercana := a
+		if f > 0.5 {
+			cercana = b
+		}
+		interpolar := func(x, y float64) float64 { return x + (y-x)*f }
+		reducidos[k] = client.TelemetryPoint{
+			Time:     t,
+			Distance: interpolar(a.Distance, b.Distance),
+			Speed:    interpolar(a.Speed, b.Speed),
+			Throttle: interpolar(a.Throttle, b.Throttle),
+			Brake:    cercana.Brake,
+			Gear:     cercana.Gear,
+			RPM:      int(math.Round(interpolar(float64(a.RPM), float64(b.RPM)))),
+			DRS:      cercana.DRS,
+		}
+	}
+	return reducidos
+}
+
+func getRitmo(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "id")
+	if !ok {
+		return
+	}
+	c.JSON(200, consultarRitmo(sessionKey))
+}
+
+func getRacePaceV2(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "session_key")
+	if !ok {
+		return
+	}
+	c.JSON(200, consultarRitmo(sessionKey))
+}
+
+func getLideres(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "id")
+	if !ok {
+		return
+	}
+	c.JSON(200, consultarLideres(sessionKey))
+}
+
+func getRaceLeadersV2(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "session_key")
+	if !ok {
+		return
+	}
+	c.JSON(200, consultarLideres(sessionKey))
+}
+
+// consultarLideres lee el lider de cada vuelta guardado en lap_leaders. Si la
+// carrera no se materializo (o la tabla no existe) se calcula.
+func consultarLideres(sessionKey int) client.RaceLeaders {
+	resultado := client.RaceLeaders{SessionKey: sessionKey, Laps: []client.LapLeader{}, Drivers: []client.LapsLed{}}
+	var lideres []client.LapLeader
+	rows, err := db.Query("SELECT lap_number, driver_number FROM lap_leaders WHERE session_key = ? ORDER BY lap_number", sessionKey)
+	if err == nil {
+		for rows.Next() {
+			var l client.LapLeader
+			rows.Scan(&l.LapNumber, &l.DriverNumber)
+			lideres = append(lideres, l)
+		}
+		rows.Close()
+	}
+	if len(lideres) == 0 {
+		lideres = calcularLideres(sessionKey)
+	}
+
+	pilotos := map[int]client.LapsLed{}
+	rows, err = db.Query("SELECT driver_number, first_name || ' ' || last_name, team_name FROM drivers")
+	if err != nil {
+		log.Println("Error lideres:", err)
+		return resultado
+	}
+	for rows.Next() {
+		var p client.LapsLed
+		rows.Scan(&p.DriverNumber, &p.Driver, &p.TeamName)
+		pilotos[p.DriverNumber] = p
+	}
+	rows.Close()
+
+	vueltasLideradas := map[int]int{}
+	for _, l := range lideres {
+		l.Driver = pilotos[l.DriverNumber].Driver
+		vueltasLideradas[l.DriverNumber]++
+		resultado.Laps = append(resultado.Laps, l)
+	}
+	for numero, n := range vueltasLideradas {
+		p := pilotos[numero]
+		p.DriverNumber, p.LapsLed = numero, n
+		resultado.Drivers = append(resultado.Drivers, p)
+	}
+	sort.Slice(resultado.Drivers, func(i, j int) bool {
+		if resultado.Drivers[i].LapsLed != resultado.Drivers[j].LapsLed {
+			return resultado.Drivers[i].LapsLed > resultado.Drivers[j].LapsLed
+		}
+		return resultado.Drivers[i].DriverNumber < resultado.Drivers[j].DriverNumber
+	})
+	return resultado
+}
+
+type cambioLider struct {
+	fecha  time.Time
+	piloto int
+}
+
+// calcularLideres cruza el historial de posiciones con las vueltas. Una
+// vuelta termina cuando el primer auto la completa: es el inicio mas
+// temprano de la vuelta siguiente o, si falta (ultima vuelta), el inicio mas
+// la duracion. El lider de la vuelta es el ultimo piloto que paso a P1 antes
+// de ese momento.
+func calcularLideres(sessionKey int) []client.LapLeader {
+	var cambios []cambioLider
+	rows, err := db.Query("SELECT date, driver_number FROM position_history WHERE session_key = ? AND position = 1", sessionKey)
+	if err != nil {
+		return nil
+	}
+	for rows.Next() {
+		var fecha string
+		var c cambioLider
+		rows.Scan(&fecha, &c.piloto)
+		if t, err := time.Parse(time.RFC3339, fecha); err == nil {
+			c.fecha = t
+			cambios = append(cambios, c)
+		}
+	}
+	rows.Close()
+	if len(cambios) == 0 {
+		return nil
+	}
+	sort.Slice(cambios, func(i, j int) bool { return cambios[i].fecha.Before(cambios[j].fecha) })
+
+	fines := map[int]time.Time{}
+	terminar := func(vuelta int, t time.Time) {
+		if f, ok := fines[vuelta]; vuelta > 0 && (!ok || t.Before(f)) {
+			fines[vuelta] = t
+		}
+	}
+	rows, err = db.Query("SELECT lap_number, date_start, lap_duration FROM laps WHERE session_key = ?", sessionKey)
+	if err != nil {
+		return nil
+	}
+	for rows.Next() {
+		var vuelta int
+		var inicio sql.NullString
+		var duracion sql.NullFloat64
+		rows.Scan(&vuelta, &inicio, &duracion)
+		desde, err := time.Parse(time.RFC3339, inicio.String)
+		if err != nil {
+			continue
+		}
+		terminar(vuelta-1, desde)
+		if duracion.Float64 > 0 {
+			terminar(vuelta, desde.Add(time.Duration(duracion.Float64*float64(time.Second))))
+		}
+	}
+	rows.Close()
+
+	var vueltas []int
+	for v := range fines {
+		vueltas = append(vueltas, v)
+	}
+	sort.Ints(vueltas)
+	var lideres []client.LapLeader
+	for _, v := range vueltas {
+		i := sort.Search(len(cambios), func(i int) bool { return cambios[i].fecha.After(fines[v]) })
+		if i == 0 {
+			continue
+		}
+		lideres = append(lideres, client.LapLeader{LapNumber: v, DriverNumber: cambios[i-1].piloto})
+	}
+	return lideres
+}
+
+// guardarLideres materializa en lap_leaders el lider de cada vuelta de las
+// carreras y sprints cargados. Corre despues de las cargas de posiciones y
+// vueltas.
+func guardarLideres() {
+	for _, key := range sesionesCargadas("Race") {
+		tx, err := db.Begin()
+		if err != nil {
+			log.Println("Error lideres:", err)
+			return
+		}
+		// Igual que en guardarResultados, una carrera se guarda completa o
+		// se sigue calculando.
+		if err := guardarLideresCarrera(tx, key); err != nil {
+			log.Println("Error guardando lider:", err)
+			tx.Rollback()
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			log.Println("Error lideres:", err)
+		}
+	}
+}
+
+// guardarLideresCarrera reemplaza dentro de tx las filas de lap_leaders de
+// una carrera. El borrado previo saca las vueltas que ya no estan en los
+// datos recargados.
+func guardarLideresCarrera(tx *sql.Tx, sessionKey int) error {
+	if _, err := tx.Exec("DELETE FROM lap_leaders WHERE session_key = ?", sessionKey); err != nil {
+		return err
+	}
+	insert := `INSERT OR REPLACE INTO lap_leaders (session_key, lap_number, driver_number) VALUES (?, ?, ?)`
+	for _, l := range calcularLideres(sessionKey) {
+		if _, err := tx.Exec(insert, sessionKey, l.LapNumber, l.DriverNumber); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Una vuelta es limpia si no supera en mas de un 7% la mediana del piloto.
+// Asi quedan fuera las vueltas de boxes y las de safety car cuando no hay
+// datos de direccion de carrera para marcarlas.
+const umbralVueltaLimpia = 1.07
+
+// Los stints mas cortos que esto no tienen vueltas suficientes para una
+// pendiente confiable.
+const minVueltasStint = 5
+
+type vueltaRitmo struct {
+	numero       int
+	duracion     float64
+	neutralizada bool
+}
+
+func consultarRitmo(sessionKey int) client.RacePace {
+	resultado := client.RacePace{SessionKey: sessionKey, Drivers: []client.DriverPace{}}
+
+	// La posicion y el ganador salen de la clasificacion, que deja atras a
+	// los descalificados.
+	posiciones := map[int]int{}
+	ganador := 0
+	for i, r := range consultarClasificacion(sessionKey) {
+		posiciones[r.DriverNumber] = r.Position
+		if i == 0 && r.Status != client.StatusDSQ && r.Status != client.StatusDNS {
+			ganador = r.DriverNumber
+		}
+	}
+
+	// La primera vuelta se descarta porque incluye la largada.
+	periodos := consultarPeriodosPista(sessionKey)
+	rows, _ := db.Query(`
+		SELECT l.driver_number, d.first_name || ' ' || d.last_name, d.team_name, l.lap_number, l.lap_duration, l.date_start
+		FROM laps l
+		JOIN drivers d ON d.driver_number = l.driver_number
+		WHERE l.session_key = ?
+		  AND l.lap_number > 1
+		  AND l.lap_duration > 0
+		  AND l.duration_sector_1 > 0
+		  AND l.duration_sector_2 > 0
+		  AND l.duration_sector_3 > 0
+		ORDER BY l.driver_number, l.lap_number
+	`, sessionKey)
+	defer rows.Close()
+
+	var pilotos []client.DriverPace
+	vueltas := map[int][]vueltaRitmo{}
+	for rows.Next() {
+		var dp client.DriverPace
+		var v vueltaRitmo
+		var inicio sql.NullString
+		rows.Scan(&dp.DriverNumber, &dp.Driver, &dp.TeamName, &v.numero, &v.duracion, &inicio)
+		v.neutralizada = neutralizada(estadoVuelta(periodos, inicio.String, v.duracion))
+		if _, visto := vueltas[dp.DriverNumber]; !visto {
+			if pos, ok := posiciones[dp.DriverNumber]; ok {
+				dp.Position = &pos
+			}
+			pilotos = append(pilotos, dp)
+		}
+		vueltas[dp.DriverNumber] = append(vueltas[dp.DriverNumber], v)
+	}
+
+	for _, dp := range pilotos {
+		todas := vueltas[dp.DriverNumber]
+		var tiempos []float64
+		for _, v := range todas {
+			if v.neutralizada {
+				dp.NeutralisedLaps++
+			} else {
+				tiempos = append(tiempos, v.duracion)
+			}
+		}
+		limite := mediana(tiempos) * umbralVueltaLimpia
+
+		// Se corta un stint en cada vuelta lenta o cuando faltan vueltas.
+		var limpias []float64
+		var stint []vueltaRitmo
+		dp.Stints = []client.PaceStint{}
+		cerrarStint := func() {
+			if len(stint) > 0 {
+				dp.Stints = append(dp.Stints, stintRitmo(stint))
+			}
+			stint = nil
+		}
+		for _, v := range todas {
+			if v.neutralizada || v.duracion > limite {
+				cerrarStint()
+				continue
+			}
+			if len(stint) > 0 && v.numero != stint[len(stint)-1].numero+1 {
+				cerrarStint()
+			}
+			stint = append(stint, v)
+			limpias = append(limpias, v.duracion)
+		}
+		cerrarStint()
+
+		dp.CleanLaps = len(limpias)
+		dp.MedianLap = mediana(limpias)
+		dp.MeanLap, dp.StdDev = mediaYDesviacion(limpias)
+
+		// La degradacion total es el promedio de los stints ponderado por vueltas.
+		var suma float64
+		var n int
+		for _, s := range dp.Stints {
+			if s.DegradationPerLap != nil {
+				suma += *s.DegradationPerLap * float64(s.Laps)
+				n += s.Laps
+			}
+		}
+		if n > 0 {
+			degradacion := suma / float64(n)
+			dp.DegradationPerLap = &degradacion
+		}
+		resultado.Drivers = append(resultado.Drivers, dp)
+	}
+
+	// Un piloto sin vueltas limpias tiene mediana 0: no tiene porcentaje y va
+	// al final.
+	for _, dp := range resultado.Drivers {
+		if dp.DriverNumber == ganador && dp.CleanLaps > 0 && dp.MedianLap > 0 {
+			ganador := dp.MedianLap
+			resultado.WinnerMedianLap = &ganador
+		}
+	}
+	if resultado.WinnerMedianLap != nil {
+		for i := range resultado.Drivers {
+			if resultado.Drivers[i].CleanLaps == 0 {
+				continue
+			}
+			pct := (resultado.Drivers[i].MedianLap - *resultado.WinnerMedianLap) / *resultado.WinnerMedianLap * 100
+			resultado.Drivers[i].GapToWinnerPct = &pct
+		}
+	}
+
+	sort.Slice(resultado.Drivers, func(i, j int) bool {
+		a, b := resultado.Drivers[i], resultado.Drivers[j]
+		if (a.CleanLaps == 0) != (b.CleanLaps == 0) {
+			return b.CleanLaps == 0
+		}
+		if a.MedianLap != b.MedianLap {
+			return a.MedianLap < b.MedianLap
+		}
+		return a.DriverNumber < b.DriverNumber
+	})
+	return resultado
+}
+
+// stintRitmo calcula la pendiente por minimos cuadrados del tiempo de vuelta
+// contra el numero de vuelta, en segundos por vuelta.
+func stintRitmo(vueltas []vueltaRitmo) client.PaceStint {
+	s := client.PaceStint{StartLap: vueltas[0].numero, EndLap: vueltas[len(vueltas)-1].numero, Laps: len(vueltas)}
+	if len(vueltas) < minVueltasStint {
+		return s
+	}
+	var sx, sy, sxx, sxy float64
+	for _, v := range vueltas {
+		x := float64(v.numero)
+		sx += x
+		sy += v.duracion
+		sxx += x * x
+		sxy += x * v.duracion
+	}
+	n := float64(len(vueltas))
+	pendiente := (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	s.DegradationPerLap = &pendiente
+	return s
+}
+
+func mediana(valores []float64) float64 {
+	if len(valores) == 0 {
+		return 0
+	}
+	ordenados := append([]float64(nil), valores...)
+	sort.Float64s(ordenados)
+	m := len(ordenados) / 2
+	if len(ordenados)%2 == 0 {
+		return (ordenados[m-1] + ordenados[m]) / 2
+	}
+	return ordenados[m]
+}
+
+func mediaYDesviacion(valores []float64) (float64, float64) {
+	if len(valores) == 0 {
+		return 0, 0
+	}
+	var suma float64
+	for _, v := range valores {
+		suma += v
+	}
+	media := suma / float64(len(valores))
+	var cuadrados float64
+	for _, v := range valores {
+		cuadrados += (v - media) * (v - media)
+	}
+	return media, math.Sqrt(cuadrados / float64(len(valores)))
+}
+
+func getPractica(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "id")
+	if !ok {
+		return
+	}
+	responderPractica(c, sessionKey)
+}
+
+func getPracticeV2(c *gin.Context) {
+	sessionKey, ok := leerEntero(c, "session_key")
+	if !ok {
+		return
+	}
+	responderPractica(c, sessionKey)
+}
+
+func responderPractica(c *gin.Context, sessionKey int) {
+	var tipo string
+	err := db.QueryRow("SELECT session_type FROM sessions WHERE session_key = ?", sessionKey).Scan(&tipo)
+	if err == sql.ErrNoRows {
+		c.JSON(404, gin.H{"error": "no existe la sesión"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tipo != "Practice" {
+		c.JSON(400, gin.H{"error": "la sesión no es una práctica libre"})
+		return
+	}
+	c.JSON(200, consultarPractica(sessionKey))
+}
+
+// Una vuelta de practica es rapida si no supera en mas de un 7% la mejor del
+// piloto; las de entrada y salida de boxes y las de enfriamiento quedan afuera.
+// Las vueltas rapidas seguidas forman una tanda, que es larga (simulacion de
+// carrera) desde minVueltasTandaLarga vueltas.
+const (
+	umbralVueltaPractica = 1.07
+	minVueltasTandaLarga = 5
+)
+
+// consultarPractica separa las vueltas de cada piloto en tandas y las
+// compara con el resultado de la carrera del mismo gran premio. Quedan afuera
+// los pilotos sin ninguna vuelta valida.
+func consultarPractica(sessionKey int) client.PracticeAnalysis {
+	analisis := client.PracticeAnalysis{SessionKey: sessionKey, Drivers: []client.DriverPractice{}}
+	var meetingKey sql.NullInt64
+	db.QueryRow("SELECT session_name, meeting_key FROM sessions WHERE session_key = ?", sessionKey).Scan(&analisis.SessionName, &meetingKey)
+	resultados := map[int]client.ResultEntry{}
+	if meetingKey.Valid {
+		mk := int(meetingKey.Int64)
+		analisis.MeetingKey = &mk
+		var carrera int
+		err := db.QueryRow("SELECT session_key FROM sessions WHERE meeting_key = ? AND session_name = ?", mk, client.SessionRace).Scan(&carrera)
+		if err == nil {
+			analisis.RaceSessionKey = &carrera
+			for _, r := range consultarClasificacion(carrera) {
+				resultados[r.DriverNumber] = r
+			}
+		}
+	}
+
+	// Compuesto de cada stint, para etiquetar las tandas.
+	compuestos := map[int][]client.TyreStint{}
+	for _, e := range consultarEstrategias(sessionKey) {
+		compuestos[e.DriverNumber] = e.Stints
+	}
+
+	rows, err := db.Query(`
+		SELECT l.driver_number, d.first_name || ' ' || d.last_name, d.team_name, l.lap_number,
+		       CASE WHEN l.lap_duration > 0 AND l.duration_sector_1 > 0 AND l.duration_sector_2 > 0 AND l.duration_sector_3 > 0
+		            THEN l.lap_duration ELSE 0 END
+		FROM laps l
+		JOIN drivers d ON d.driver_number = l.driver_number
+		WHERE l.session_key = ?
+		ORDER BY l.driver_number, l.lap_number
+	`, sessionKey)
+	if err != nil {
+		log.Println("Error practica:", err)
+		return analisis
+	}
+	defer rows.Close()
+	var pilotos []client.DriverPractice
+	vueltas := map[int][]vueltaRitmo{}
+	for rows.Next() {
+		var dp client.DriverPractice
+		var v vueltaRitmo
+		rows.Scan(&dp.DriverNumber, &dp.Driver, &dp.TeamName, &v.numero, &v.duracion)
+		if _, visto := vueltas[dp.DriverNumber]; !visto {
+			pilotos = append(pilotos, dp)
+		}
+		vueltas[dp.DriverNumber] = append(vueltas[dp.DriverNumber], v)
+	}
+
+	for _, dp := range pilotos {
+		todas := vueltas[dp.DriverNumber]
+		dp.TotalLaps = len(todas)
+		dp.Runs = []client.PracticeRun{}
+		for _, v := range todas {
+			if v.duracion > 0 && (dp.BestLap == 0 || v.duracion < dp.BestLap) {
+				dp.BestLap = v.duracion
+			}
+		}
+		if dp.BestLap == 0 {
+			continue
+		}
+		limite := dp.BestLap * umbralVueltaPractica
+
+		var limpias, largas []float64
+		var tanda []vueltaRitmo
+		cerrarTanda := func() {
+			if len(tanda) > 0 {
+				run := tandaPractica(tanda, compuestos[dp.DriverNumber])
+				if run.LongRun {
+					for _, v := range tanda {
+						largas = append(largas, v.duracion)
+					}
+				} else if dp.ShortRunPace == nil || run.BestLap < *dp.ShortRunPace {
+					mejor := run.BestLap
+					dp.ShortRunPace = &mejor
+				}
+				dp.Runs = append(dp.Runs, run)
+			}
+			tanda = nil
+		}
+		for _, v := range todas {
+			if v.duracion == 0 || v.duracion > limite {
+				cerrarTanda()
+				continue
+			}
+			if len(tanda) > 0 && v.numero != tanda[len(tanda)-1].numero+1 {
+				cerrarTanda()
+			}
+			tanda = append(tanda, v)
+			limpias = append(limpias, v.duracion)
+		}
+		cerrarTanda()
+
+		dp.CleanLaps = len(limpias)
+		dp.CleanLapPace = mediana(limpias)
+		if len(largas) > 0 {
+			ritmo := mediana(largas)
+			dp.LongRunPace = &ritmo
+			dp.LongRunLaps = len(largas)
+		}
+		if r, ok := resultados[dp.DriverNumber]; ok {
+			pos := r.Position
+			dp.RacePosition = &pos
+			dp.RaceStatus = r.Status
+		}
+		analisis.Drivers = append(analisis.Drivers, dp)
+	}
+
+	sort.Slice(analisis.Drivers, func(i, j int) bool {
+		return analisis.Drivers[i].BestLap < analisis.Drivers[j].BestLap
+	})
+	for i := range analisis.Drivers {
+		analisis.Drivers[i].Position = i + 1
+	}
+	return analisis
+}
+
+// tandaPractica resume una tanda de vueltas rapidas consecutivas.
+func tandaPractica(vueltas []vueltaRitmo, stints []client.TyreStint) client.PracticeRun {
+	tiempos := make([]float64, len(vueltas))
+	for i, v := range vueltas {
+		tiempos[i] = v.duracion
+	}
+	ritmo := stintRitmo(vueltas)
+	run := client.PracticeRun{
+		StartLap:          ritmo.StartLap,
+		EndLap:            ritmo.EndLap,
+		Laps:              ritmo.Laps,
+		MedianLap:         mediana(tiempos),
+		DegradationPerLap: ritmo.DegradationPerLap,
+		LongRun:           len(vueltas) >= minVueltasTandaLarga,
+	}
+	for _, t := range tiempos {
+		if run.BestLap == 0 || t < run.BestLap {
+			run.BestLap = t
+		}
+	}
+	for _, s := range stints {
+		if run.StartLap >= s.LapStart && run.StartLap <= s.LapEnd {
+			run.Compound = s.Compound
+		}
+	}
+	return run
+}
+
+func getComparacion(c *gin.Context) {
+	a, errA := strconv.Atoi(c.Query("a"))
+	b, errB := strconv.Atoi(c.Query("b"))
+	if errA != nil || errB != nil {
+		c.JSON(400, gin.H{"error": "a y b deben ser números de piloto"})
+		return
+	}
+	if a == b {
+		c.JSON(400, gin.H{"error": "a y b deben ser pilotos distintos"})
+		return
+	}
+	h2h, err := consultarComparacion(a, b)
+	if err == sql.ErrNoRows {
+		c.JSON(404, gin.H{"error": "no existe alguno de los pilotos"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, h2h)
+}
+
+func consultarComparacion(a, b int) (client.HeadToHead, error) {
+	h := client.HeadToHead{Races: []client.HeadToHeadRace{}}
+	var err error
+	if h.DriverA, err = consultarPiloto(a); err != nil {
+		return h, err
+	}
+	if h.DriverB, err = consultarPiloto(b); err != nil {
+		return h, err
+	}
+
+	// Se reutiliza la consulta del detalle de piloto y se cruzan las
+	// carreras que largaron ambos.
+	carrerasB := map[int]client.RaceResult{}
+	for _, r := range consultarDetallePiloto(b).RaceResults {
+		carrerasB[r.SessionKey] = r
+	}
+	fechas := map[int]string{}
+	finDeSemana := map[int]int{}
+	rows, _ := db.Query("SELECT session_key, date_start, COALESCE(meeting_key, 0) FROM sessions WHERE session_type = 'Race'")
+	for rows.Next() {
+		var key, fds int
+		var fecha string
+		rows.Scan(&key, &fecha, &fds)
+		fechas[key], finDeSemana[key] = fecha, fds
+	}
+	rows.Close()
+	qualyA, qualyB := posicionesClasificacion(a), posicionesClasificacion(b)
+
+	var sumaVuelta, sumaVelocidad float64
+	var nVuelta int
+	for _, ra := range consultarDetallePiloto(a).RaceResults {
+		rb, ok := carrerasB[ra.SessionKey]
+		if !ok || ra.Status == client.StatusDNS || rb.Status == client.StatusDNS {
+			continue
+		}
+		r := client.HeadToHeadRace{
+			SessionKey:       ra.SessionKey,
+			CircuitShortName: ra.CircuitShortName,
+			DateStart:        fechas[ra.SessionKey],
+			PositionA:        ra.Position,
+			PositionB:        rb.Position,
+			BestLapA:         ra.BestLapDuration,
+			BestLapB:         rb.BestLapDuration,
+			TopSpeedA:        ra.MaxSpeed,
+			TopSpeedB:        rb.MaxSpeed,
+			TopSpeedDelta:    ra.MaxSpeed - rb.MaxSpeed,
+		}
+		// Sin alguna de las posiciones, o con la misma, no suma ninguno.
+		switch {
+		case ra.Position <= 0 || rb.Position <= 0 || ra.Position == rb.Position:
+		case ra.Position < rb.Position:
+			r.Ahead = a
+			h.RaceHeadToHead.A++
+		default:
+			r.Ahead = b
+			h.RaceHeadToHead.B++
+		}
+		if q, ok := qualyA[finDeSemana[ra.SessionKey]]; ok {
+			r.QualifyingA = &q
+		}
+		if q, ok := qualyB[finDeSemana[ra.SessionKey]]; ok {
+			r.QualifyingB = &q
+		}
+		if ra.BestLapDuration > 0 && rb.BestLapDuration > 0 {
+			delta := ra.BestLapDuration - rb.BestLapDuration
+			r.BestLapDelta = &delta
+			sumaVuelta += delta
+			nVuelta++
+		}
+		sumaVelocidad += r.TopSpeedDelta
+		h.Races = append(h.Races, r)
+	}
+	sort.Slice(h.Races, func(i, j int) bool {
+		return h.Races[i].DateStart < h.Races[j].DateStart
+	})
+	h.RacesBothStarted = len(h.Races)
+	if nVuelta > 0 {
+		prom := sumaVuelta / float64(nVuelta)
+		h.AvgBestLapDelta = &prom
+	}
+	if len(h.Races) > 0 {
+		prom := sumaVelocidad / float64(len(h.Races))
+		h.AvgTopSpeedDelta = &prom
+	}
+
+	for fds, qa := range qualyA {
+		qb, ok := qualyB[fds]
+		switch {
+		case !ok || qa <= 0 || qb <= 0 || qa == qb:
+		case qa < qb:
+			h.QualifyingHeadToHead.A++
+		default:
+			h.QualifyingHeadToHead.B++
+		}
+	}
+	return h, nil
+}
+
+func consultarPiloto(driverNumber int) (client.HeadToHeadDriver, error) {
+	var p client.HeadToHeadDriver
+	err := db.QueryRow(`
+		SELECT driver_number, first_name || ' ' || last_name, team_name
+		FROM drivers
+		WHERE driver_number = ?
+	`, driverNumber).Scan(&p.DriverNumber, &p.Driver, &p.TeamName)
+	return p, err
+}
+
+// posicionesClasificacion devuelve la posicion en clasificacion del piloto
+// indexada por meeting_key, para cruzarla con la carrera del mismo fin de
+// semana. Las sesiones sin meeting_key quedan afuera.
+func posicionesClasificacion(driverNumber int) map[int]int {
+	rows, _ := db.Query(`
+		SELECT s.meeting_key, p.position
+		FROM positions p
+		JOIN sessions s ON s.session_key = p.session_key
+		WHERE p.driver_number = ? AND s.session_name = 'Qualifying' AND s.meeting_key > 0
+	`, driverNumber)
+	defer rows.Close()
+	posiciones := map[int]int{}
+	for rows.Next() {
+		var fds, pos int
+		rows.Scan(&fds, &pos)
+		posiciones[fds] = pos
+	}
+	return posiciones
+}
+
+func cargarDatosDesdeOpenF1() {
+	cargarPilotos()
+	cargarSesiones()
+	cargarReuniones()
+	cargarEquiposPorSesion()
+	cargarPosiciones()
+	cargarVueltas()
+	cargarStints()
+	cargarParadas()
+	cargarClima()
+	cargarDireccionCarrera()
+	cargarIntervalos()
+	guardarVueltasRapidas()
+	guardarResultados()
+	guardarLideres()
+}
+
+func cargarPilotos() {
+	type Driver struct {
+		DriverNumber int    `json:"driver_number"`
+		FirstName    string `json:"first_name"`
+		LastName     string `json:"last_name"`
+		NameAcronym  string `json:"name_acronym"`
+		TeamName     string `json:"team_name"`
+		CountryCode  string `json:"country_code"`
+	}
+	insert := `INSERT OR IGNORE INTO drivers (driver_number, first_name, last_name, name_acronym, team_name, country_code) VALUES (?, ?, ?, ?, ?, ?)`
+	sessions := map[int][]int{
+		9574: {1, 2, 3, 4, 10, 11, 14, 16, 18, 20, 22, 23, 24, 27, 31, 44, 55, 63, 77, 81},
+		9636: {30, 50, 43},
+	}
+	for sessionKey, permitidos := range sessions {
+		url := fmt.Sprintf("https://api.openf1.org/v1/drivers?session_key=%d", sessionKey)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Println("Error al obtener pilotos:", err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			log.Printf("Error HTTP %d: %s", resp.StatusCode, string(body))
+			continue
+		}
+		var drivers []Driver
+		if err := json.Unmarshal(body, &drivers); err != nil {
+			log.Println("Error decodificando pilotos:", err)
+			continue
+		}
+		for _, d := range drivers {
+			if contains(permitidos, d.DriverNumber) {
+				_, _ = db.Exec(insert, d.DriverNumber, d.FirstName, d.LastName, d.NameAcronym, d.TeamName, d.CountryCode)
+			}
+		}
+	}
+}
+
+func cargarSesiones() {
+	// Se carga el fin de semana completo; las clasificaciones tambien sirven
+	// para las comparaciones entre pilotos. Los sprints son de tipo Race y se
+	// distinguen por session_name.
+	for _, nombre := range []string{"Practice 1", "Practice 2", "Practice 3", "Sprint Qualifying", "Sprint", "Qualifying", "Race"} {
+		direccion := fmt.Sprintf("https://api.openf1.org/v1/sessions?session_name=%s&year=2024", url.QueryEscape(nombre))
+		resp, err := http.Get(direccion)
+		if err != nil {
+			log.Println("Error al obtener sesiones:", err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			log.Printf("Error HTTP %d: %s", resp.StatusCode, string(body))
+			continue
+		}
+		var sessions []Session
+		if err := json.Unmarshal(body, &sessions); err != nil {
+			log.Println("Error decodificando sesiones:", err)
+			continue
+		}
+		insert := `INSERT OR IGNORE INTO sessions (session_key, session_name, session_type, location, country_name, year, circuit_short_name, date_start) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+		// meeting_key va aparte para completar las sesiones cargadas antes de
+		// que existiera la columna.
+		for _, s := range sessions {
+			_, _ = db.Exec(insert, s.SessionKey, s.SessionName, s.SessionType, s.Location, s.CountryName, s.Year, s.CircuitShortName, s.DateStart)
+			_, _ = db.Exec("UPDATE sessions SET meeting_key = ? WHERE session_key = ?", s.MeetingKey, s.SessionKey)
+		}
+	}
+}
+
+// cargarReuniones carga los grandes premios del año, que agrupan por
+// meeting_key las sesiones de cada fin de semana.
+func cargarReuniones() {
+	type Meeting struct {
+		MeetingKey          int    `json:"meeting_key"`
+		MeetingName         string `json:"meeting_name"`
+		MeetingOfficialName string `json:"meeting_official_name"`
+		Location            string `json:"location"`
+		CountryName         string `json:"country_name"`
+		CircuitShortName    string `json:"circuit_short_name"`
+		DateStart           string `json:"date_start"`
+		Year                int    `json:"year"`
+	}
+	var reuniones []Meeting
+	if err := obtenerOpenF1("https://api.openf1.org/v1/meetings?year=2024", &reuniones); err != nil {
+		log.Println("Error grandes premios:", err)
+		return
+	}
+	insert := `INSERT OR REPLACE INTO meetings (meeting_key, meeting_name, meeting_official_name, location, country_name, circuit_short_name, date_start, year) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, m := range reuniones {
+		if _, err := db.Exec(insert, m.MeetingKey, m.MeetingName, m.MeetingOfficialName, m.Location, m.CountryName, m.CircuitShortName, m.DateStart, m.Year); err != nil {
+			log.Println("Error guardando gran premio:", err)
+			break
+		}
+	}
+}
+
+// cargarEquiposPorSesion guarda el equipo de cada piloto en cada sesion
+// cargada, para no depender del equipo actual cuando alguien cambio de equipo
+// durante el año.
+func cargarEquiposPorSesion() {
+	type Driver struct {
+		DriverNumber int    `json:"driver_number"`
+		SessionKey   int    `json:"session_key"`
+		TeamName     string `json:"team_name"`
+	}
+	insert := `INSERT OR REPLACE INTO driver_sessions (session_key, driver_number, team_name) VALUES (?, ?, ?)`
+	for _, key := range sesionesCargadas("") {
+		var pilotos []Driver
+		if err := obtenerOpenF1(fmt.Sprintf("https://api.openf1.org/v1/drivers?session_key=%d", key), &pilotos); err != nil {
+			log.Println("Error equipos por sesion:", err)
+			continue
+		}
+		for _, d := range pilotos {
+			if _, err := db.Exec(insert, key, d.DriverNumber, d.TeamName); err != nil {
+				log.Println("Error guardando equipo por sesion:", err)
+				break
+			}
+		}
+	}
+}
+
+func cargarPosiciones() {
+	rows, _ := db.Query("SELECT session_key FROM sessions")
+	var sessionKeys []int
+	for rows.Next() {
+		var k int
+		rows.Scan(&k)
+		sessionKeys = append(sessionKeys, k)
+	}
+	type Position struct {
+		DriverNumber int    `json:"driver_number"`
+		SessionKey   int    `json:"session_key"`
+		Position     int    `json:"position"`
+		Date         string `json:"date"`
+	}
+	// OpenF1 manda cada cambio de posicion en orden; en positions se queda el
+	// ultimo, que es la posicion final de la sesion, y en position_history
+	// todos, para saber quien lideraba cada vuelta.
+	insert := `INSERT INTO positions (driver_number, session_key, position, date) VALUES (?, ?, ?, ?)
+		ON CONFLICT(driver_number, session_key) DO UPDATE SET position = excluded.position, date = excluded.date
+		WHERE excluded.date >= positions.date`
+	insertHistorial := `INSERT OR IGNORE INTO position_history (session_key, driver_number, date, position) VALUES (?, ?, ?, ?)`
+	for _, key := range sessionKeys {
+		url := fmt.Sprintf("https://api.openf1.org/v1/position?session_key=%d", key)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Println("Error posiciones:", err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			log.Printf("Error HTTP %d: %s", resp.StatusCode, string(body))
+			continue
+		}
+		var positions []Position
+		if err := json.Unmarshal(body, &positions); err != nil {
+			log.Println("Error decodificando posiciones:", err)
+			continue
+		}
+		for _, p := range positions {
+			_, _ = db.Exec(insert, p.DriverNumber, p.SessionKey, p.Position, p.Date)
+			_, _ = db.Exec(insertHistorial, p.SessionKey, p.DriverNumber, p.Date, p.Position)
+		}
+	}
+}
+
+func cargarVueltas() {
+	rows, _ := db.Query("SELECT session_key FROM sessions")
+	var sessionKeys []int
+	for rows.Next() {
+		var k int
+		rows.Scan(&k)
+		sessionKeys = append(sessionKeys, k)
+	}
+	type Lap struct {
+		DriverNumber     int     `json:"driver_number"`
+		SessionKey       int     `json:"session_key"`
+		LapNumber        int     `json:"lap_number"`
+		LapDuration      float64 `json:"lap_duration"`
+		DurationSector1  float64 `json:"duration_sector_1"`
+		DurationSector2  float64 `json:"duration_sector_2"`
+		DurationSector3  float64 `json:"duration_sector_3"`
+		StSpeed          float64 `json:"st_speed"`
+		DateStart        string  `json:"date_start"`
+	}
+	insert := `INSERT OR IGNORE INTO laps (driver_number, session_key, lap_number, lap_duration, duration_sector_1, duration_sector_2, duration_sector_3, st_speed, date_start) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, key := range sessionKeys {
+		url := fmt.Sprintf("https://api.openf1.org/v1/laps?session_key=%d", key)
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Println("Error vueltas:", err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			log.Printf("Error HTTP %d: %s", resp.StatusCode, string(body))
+			continue
+		}
+		var laps []Lap
+		if err := json.Unmarshal(body, &laps); err != nil {
+			log.Println("Error decodificando vueltas:", err)
+			continue
+		}
+		for _, l := range laps {
+			_, _ = db.Exec(insert, l.DriverNumber, l.SessionKey, l.LapNumber, l.LapDuration, l.DurationSector1, l.DurationSector2, l.DurationSector3, l.StSpeed, l.DateStart)
+		}
+	}
+}
+
+// obtenerOpenF1 hace un GET a la API de OpenF1 y decodifica la respuesta.
+func obtenerOpenF1(url string, destino interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, destino)
+}
+
+// sesionesCargadas devuelve las session_key de las sesiones de un tipo
+// ("Race", "Qualifying", ...) o de todas si tipo es "".
+func sesionesCargadas(tipo string) []int {
+	rows, err := db.Query("SELECT session_key FROM sessions WHERE ? = '' OR session_type = ?", tipo, tipo)
+	if err != nil {
+		log.Println("Error leyendo sesiones:", err)
+		return nil
+	}
+	defer rows.Close()
+	var keys []int
+	for rows.Next() {
+		var k int
+		rows.Scan(&k)
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func cargarStints() {
+	type Stint struct {
+		DriverNumber   int    `json:"driver_number"`
+		SessionKey     int    `json:"session_key"`
+		StintNumber    int    `json:"stint_number"`
+		Compound       string `json:"compound"`
+		LapStart       int    `json:"lap_start"`
+		LapEnd         int    `json:"lap_end"`
+		TyreAgeAtStart int    `json:"tyre_age_at_start"`
+	}
+	insert := `INSERT OR REPLACE INTO stints (driver_number, session_key, stint_number, compound, lap_start, lap_end, tyre_age_at_start) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	// En las practicas sirven para saber con que compuesto se hizo cada tanda.
+	for _, key := range append(sesionesCargadas("Race"), sesionesCargadas("Practice")...) {
+		var stints []Stint
+		if err := obtenerOpenF1(fmt.Sprintf("https://api.openf1.org/v1/stints?session_key=%d", key), &stints); err != nil {
+			log.Println("Error stints:", err)
+			continue
+		}
+		for _, s := range stints {
+			if _, err := db.Exec(insert, s.DriverNumber, s.SessionKey, s.StintNumber, s.Compound, s.LapStart, s.LapEnd, s.TyreAgeAtStart); err != nil {
+				log.Println("Error guardando stint:", err)
+				break
+			}
+		}
+	}
+}
+
+func cargarParadas() {
+	type Pit struct {
+		DriverNumber int      `json:"driver_number"`
+		SessionKey   int      `json:"session_key"`
+		LapNumber    int      `json:"lap_number"`
+		PitDuration  *float64 `json:"pit_duration"`
+		Date         string   `json:"date"`
+	}
+	insert := `INSERT OR REPLACE INTO pit_stops (driver_number, session_key, lap_number, pit_duration, date) VALUES (?, ?, ?, ?, ?)`
+	for _, key := range sesionesCargadas("Race") {
+		var paradas []Pit
+		if err := obtenerOpenF1(fmt.Sprintf("https://api.openf1.org/v1/pit?session_key=%d", key), &paradas); err != nil {
+			log.Println("Error paradas:", err)
+			continue
+		}
+		for _, p := range paradas {
+			if _, err := db.Exec(insert, p.DriverNumber, p.SessionKey, p.LapNumber, p.PitDuration, p.Date); err != nil {
+				log.Println("Error guardando parada:", err)
+				break
+			}
+		}
+	}
+}
+
+func cargarClima() {
+	type Weather struct {
+		SessionKey       int     `json:"session_key"`
+		Date             string  `json:"date"`
+		AirTemperature   float64 `json:"air_temperature"`
+		TrackTemperature float64 `json:"track_temperature"`
+		Humidity         float64 `json:"humidity"`
+		Pressure         float64 `json:"pressure"`
+		Rainfall         int     `json:"rainfall"`
+		WindDirection    int     `json:"wind_direction"`
+		WindSpeed        float64 `json:"wind_speed"`
+	}
+	insert := `INSERT OR REPLACE INTO weather (session_key, date, air_temperature, track_temperature, humidity, pressure, rainfall, wind_direction, wind_speed) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, key := range sesionesCargadas("") {
+		var mediciones []Weather
+		if err := obtenerOpenF1(fmt.Sprintf("https://api.openf1.org/v1/weather?session_key=%d", key), &mediciones); err != nil {
+			log.Println("Error clima:", err)
+			continue
+		}
+		for _, w := range mediciones {
+			if _, err := db.Exec(insert, w.SessionKey, w.Date, w.AirTemperature, w.TrackTemperature, w.Humidity, w.Pressure, w.Rainfall, w.WindDirection, w.WindSpeed); err != nil {
+				log.Println("Error guardando clima:", err)
+				break
+			}
+		}
+	}
+}
+
+func cargarDireccionCarrera() {
+	// sector, driver_number y lap_number vienen en null cuando no aplican.
+	type RaceControl struct {
+		SessionKey   int     `json:"session_key"`
+		Date         string  `json:"date"`
+		Category     string  `json:"category"`
+		Flag         *string `json:"flag"`
+		Scope        *string `json:"scope"`
+		Sector       *int    `json:"sector"`
+		DriverNumber *int    `json:"driver_number"`
+		LapNumber    *int    `json:"lap_number"`
+		Message      string  `json:"message"`
+	}
+	insert := `INSERT OR REPLACE INTO race_control (session_key, date, category, flag, scope, sector, driver_number, lap_number, message) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, key := range sesionesCargadas("") {
+		var mensajes []RaceControl
+		if err := obtenerOpenF1(fmt.Sprintf("https://api.openf1.org/v1/race_control?session_key=%d", key), &mensajes); err != nil {
+			log.Println("Error direccion de carrera:", err)
+			continue
+		}
+		for _, m := range mensajes {
+			if _, err := db.Exec(insert, m.SessionKey, m.Date, m.Category, m.Flag, m.Scope, m.Sector, m.DriverNumber, m.LapNumber, m.Message); err != nil {
+				log.Println("Error guardando mensaje de direccion de carrera:", err)
+				break
+			}
+		}
+	}
+}
+
+// muestraTelemetria es una muestra de /car_data. Se guarda en 12 bytes en vez
+// de una fila por muestra: OpenF1 manda unas cuatro por segundo por piloto.
+type muestraTelemetria struct {
+	ms         uint32 // desde el inicio de la vuelta
+	velocidad  uint16
+	acelerador uint8
+	freno      uint8
+	marcha     uint8
+	drs        uint8
+	rpm        uint16
+}
+
+const tamMuestra = 12
+
+func codificarTelemetria(muestras []muestraTelemetria) []byte {
+	data := make([]byte, len(muestras)*tamMuestra)
+	for i, m := range muestras {
+		b := data[i*tamMuestra:]
+		binary.LittleEndian.PutUint32(b[0:], m.ms)
+		binary.LittleEndian.PutUint16(b[4:], m.velocidad)
+		b[6], b[7], b[8], b[9] = m.acelerador, m.freno, m.marcha, m.drs
+		binary.LittleEndian.PutUint16(b[10:], m.rpm)
+	}
+	return data
+}
+
+func decodificarTelemetria(data []byte) ([]muestraTelemetria, error) {
+	if len(data)%tamMuestra != 0 {
+		return nil, fmt.Errorf("telemetría corrupta: %d bytes", len(data))
+	}
+	muestras := make([]muestraTelemetria, len(data)/tamMuestra)
+	for i := range muestras {
+		b := data[i*tamMuestra:]
+		muestras[i] = muestraTelemetria{
+			ms:         binary.LittleEndian.Uint32(b[0:]),
+			velocidad:  binary.LittleEndian.Uint16(b[4:]),
+			acelerador: b[6],
+			freno:      b[7],
+			marcha:     b[8],
+			drs:        b[9],
+			rpm:        binary.LittleEndian.Uint16(b[10:]),
+		}
+	}
+	return muestras, nil
+}
+
+// cargarTelemetria baja /car_data de las sesiones y pilotos pedidos y reparte
+// las muestras en vueltas segun el date_start de laps, por lo que tiene que
+// correr despues de cargarVueltas. Una vuelta va desde su inicio hasta el
+// inicio de la siguiente; la primera vuelta de carrera no suele tener
+// date_start y queda sin telemetria.
+func cargarTelemetria(sesiones, pilotos []int) {
+	type CarData struct {
+		Date     string  `json:"date"`
+		Speed    float64 `json:"speed"`
+		Throttle float64 `json:"throttle"`
+		Brake    float64 `json:"brake"`
+		NGear    int     `json:"n_gear"`
+		RPM      float64 `json:"rpm"`
+		DRS      int     `json:"drs"`
+	}
+	type vuelta struct {
+		numero       int
+		desde, hasta time.Time
+	}
+	insert := `INSERT OR REPLACE INTO car_data (session_key, driver_number, lap_number, samples, max_speed, data) VALUES (?, ?, ?, ?, ?, ?)`
+	for _, key := range sesiones {
+		numeros := pilotos
+		if len(numeros) == 0 {
+			rows, err := db.Query("SELECT DISTINCT driver_number FROM laps WHERE session_key = ?", key)
+			if err != nil {
+				log.Println("Error telemetria:", err)
+				continue
+			}
+			for rows.Next() {
+				var n int
+				rows.Scan(&n)
+				numeros = append(numeros, n)
+			}
+			rows.Close()
+		}
+
+		for _, numero := range numeros {
+			var vueltas []vuelta
+			rows, err := db.Query(`
+				SELECT lap_number, date_start, lap_duration
+				FROM laps
+				WHERE session_key = ? AND driver_number = ? AND date_start != ''
+				ORDER BY lap_number
+			`, key, numero)
+			if err != nil {
+				log.Println("Error telemetria:", err)
+				continue
+			}
+			for rows.Next() {
+				var v vuelta
+				var inicio string
+				var duracion float64
+				rows.Scan(&v.numero, &inicio, &duracion)
+				if v.desde, err = time.Parse(time.RFC3339, inicio); err != nil {
+					continue
+				}
+				v.hasta = v.desde.Add(time.Duration(duracion * float64(time.Second)))
+				if n := len(vueltas); n > 0 && vueltas[n-1].numero == v.numero-1 {
+					vueltas[n-1].hasta = v.desde
+				}
+				vueltas = append(vueltas, v)
+			}
+			rows.Close()
+			if len(vueltas) == 0 {
+				continue
+			}
+
+			var datos []CarData
+			url := fmt.Sprintf("https://api.openf1.org/v1/car_data?session_key=%d&driver_number=%d", key, numero)
+			if err := obtenerOpenF1(url, &datos); err != nil {
+				log.Println("Error telemetria:", err)
+				continue
+			}
+			porVuelta := map[int][]muestraTelemetria{}
+			maximas := map[int]float64{}
+			i := 0
+			for _, d := range datos {
+				t, err := time.Parse(time.RFC3339, d.Date)
+				if err != nil {
+					continue
+				}
+				for i < len(vueltas) && !t.Before(vueltas[i].hasta) {
+					i++
+				}
+				if i == len(vueltas) {
+					break
+				}
+				if t.Before(vueltas[i].desde) {
+					continue
+				}
+				v := vueltas[i]
+				porVuelta[v.numero] = append(porVuelta[v.numero], muestraTelemetria{
+					ms:         uint32(t.Sub(v.desde).Milliseconds()),
+					velocidad:  uint16(d.Speed),
+					acelerador: uint8(d.Throttle),
+					freno:      uint8(d.Brake),
+					marcha:     uint8(d.NGear),
+					drs:        uint8(d.DRS),
+					rpm:        uint16(d.RPM),
+				})
+				maximas[v.numero] = math.Max(maximas[v.numero], d.Speed)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				log.Println("Error telemetria:", err)
+				continue
+			}
+			// Las vueltas de un piloto se guardan todas o ninguna.
+			var errInsert error
+			for vuelta, muestras := range porVuelta {
+				if _, errInsert = tx.Exec(insert, key, numero, vuelta, len(muestras), maximas[vuelta], codificarTelemetria(muestras)); errInsert != nil {
+					break
+				}
+			}
+			if errInsert != nil {
+				log.Println("Error guardando telemetria:", errInsert)
+				tx.Rollback()
+				continue
+			}
+			if err := tx.Commit(); err != nil {
+				log.Println("Error telemetria:", err)
+				continue
+			}
+			log.Printf("Telemetría de la sesión %d, piloto %d: %d muestras en %d vueltas", key, numero, len(datos), len(porVuelta))
+		}
+	}
+}
+
+// listaEnteros interpreta una lista de números separados por coma. Una lista
+// vacia devuelve nil.
+func listaEnteros(lista string) ([]int, error) {
+	var numeros []int
+	if strings.TrimSpace(lista) == "" {
+		return nil, nil
+	}
+	for _, n := range strings.Split(lista, ",") {
+		numero, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return nil, fmt.Errorf("%q no es un número", n)
+		}
+		numeros = append(numeros, numero)
+	}
+	return numeros, nil
+}
+
+// leerDiferencia interpreta gap_to_leader e interval de /intervals: un numero
+// de segundos, null, o un texto como "+1 LAP" cuando el piloto esta doblado.
+func leerDiferencia(raw json.RawMessage) (*float64, int) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, 0
+	}
+	var segundos float64
+	if err := json.Unmarshal(raw, &segundos); err == nil {
+		return &segundos, 0
+	}
+	var texto string
+	if err := json.Unmarshal(raw, &texto); err == nil {
+		var vueltas int
+		if _, err := fmt.Sscanf(texto, "+%d LAP", &vueltas); err == nil {
+			return nil, vueltas
+		}
+	}
+	return nil, 0
+}
+
+func cargarIntervalos() {
+	type Interval struct {
+		SessionKey   int             `json:"session_key"`
+		DriverNumber int             `json:"driver_number"`
+		Date         string          `json:"date"`
+		GapToLeader  json.RawMessage `json:"gap_to_leader"`
+		Interval     json.RawMessage `json:"interval"`
+	}
+	insert := `INSERT OR REPLACE INTO intervals (session_key, driver_number, date, gap_to_leader, interval, laps_behind) VALUES (?, ?, ?, ?, ?, ?)`
+	for _, key := range sesionesCargadas("Race") {
+		var intervalos []Interval
+		if err := obtenerOpenF1(fmt.Sprintf("https://api.openf1.org/v1/intervals?session_key=%d", key), &intervalos); err != nil {
+			log.Println("Error intervalos:", err)
+			continue
+		}
+		// Son miles de filas por carrera, se guardan en una sola transaccion.
+		tx, err := db.Begin()
+		if err != nil {
+			log.Println("Error intervalos:", err)
+			continue
+		}
+		// Una carrera se guarda completa o no se guarda, para no graficar
+		// diferencias a medias.
+		var errInsert error
+		for _, in := range intervalos {
+			gap, vueltas := leerDiferencia(in.GapToLeader)
+			intervalo, _ := leerDiferencia(in.Interval)
+			if _, errInsert = tx.Exec(insert, in.SessionKey, in.DriverNumber, in.Date, gap, intervalo, vueltas); errInsert != nil {
+				break
+			}
+		}
+		if errInsert != nil {
+			log.Println("Error guardando intervalo:", errInsert)
+			tx.Rollback()
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			log.Println("Error intervalos:", err)
+		}
+	}
+}
+
+const (
+	limitPorDefecto = 20
+	limitMaximo     = 100
+)
+
+func nuevaPaginacion(total, limit, offset int) client.Pagination {
+	p := client.Pagination{Total: total, Limit: limit, Offset: offset}
+	if offset+limit < total {
+		next := offset + limit
+		p.NextOffset = &next
+	}
+	return p
+}
+
+// leerPaginacion obtiene limit y offset de la query string.
+func leerPaginacion(c *gin.Context) (int, int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(limitPorDefecto)))
+	if err != nil || limit < 1 || limit > limitMaximo {
+		return 0, 0, fmt.Errorf("limit debe estar entre 1 y %d", limitMaximo)
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, fmt.Errorf("offset debe ser un número mayor o igual a 0")
+	}
+	return limit, offset, nil
+}
+
+// leerOrden arma el ORDER BY a partir del parametro sort. Solo se aceptan los
+// campos de la lista; un "-" al inicio ordena de forma descendente. Al final
+// se ordena siempre por clave, la clave primaria de la tabla, para que las
+// filas con el mismo valor no cambien de pagina entre un offset y otro.
+func leerOrden(c *gin.Context, campos map[string]string, porDefecto, clave string) (string, error) {
+	sortParam := c.DefaultQuery("sort", porDefecto)
+	var partes []string
+	for _, campo := range strings.Split(sortParam, ",") {
+		dir := "ASC"
+		if strings.HasPrefix(campo, "-") {
+			dir = "DESC"
+			campo = campo[1:]
+		}
+		col, ok := campos[campo]
+		if !ok {
+			return "", fmt.Errorf("no se puede ordenar por %q", campo)
+		}
+		partes = append(partes, col+" "+dir)
+		if col == clave {
+			clave = ""
+		}
+	}
+	if clave != "" {
+		partes = append(partes, clave+" ASC")
+	}
+	return " ORDER BY " + strings.Join(partes, ", "), nil
+}
+
+func clausulaWhere(filtros []string) string {
+	if len(filtros) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(filtros, " AND ")
+}
+
+func contains(slice []int, val int) bool {
+	for _, v := range slice {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
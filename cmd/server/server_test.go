@@ -0,0 +1,558 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"tarea1sd/client"
+)
+
+// TestRutasDocumentadas arma el documento desde rutasV1 y rutasV2 y lo cruza
+// con las rutas que registra gin.
+func TestRutasDocumentadas(t *testing.T) {
+	doc, err := generarOpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	registrarRutas(r.Group("/api/v1"), rutasV1)
+	registrarRutas(r.Group("/api/v2"), rutasV2)
+	operaciones := 0
+	for _, metodos := range doc.Paths {
+		operaciones += len(metodos)
+	}
+	if rutas := r.Routes(); len(rutas) != operaciones {
+		t.Errorf("gin tiene %d rutas y el documento %d operaciones", len(rutas), operaciones)
+	}
+
+	for _, rt := range r.Routes() {
+		partes := strings.Split(rt.Path, "/")
+		var enPath []string
+		for i, p := range partes {
+			if strings.HasPrefix(p, ":") {
+				enPath = append(enPath, p[1:])
+				partes[i] = "{" + p[1:] + "}"
+			}
+		}
+		path := strings.Join(partes, "/")
+		op, ok := doc.Paths[path][strings.ToLower(rt.Method)]
+		if !ok {
+			t.Errorf("%s %s no está documentada", rt.Method, rt.Path)
+			continue
+		}
+		declarados := map[string]bool{}
+		for _, p := range op.Parameters {
+			if p.In == "path" {
+				declarados[p.Name] = true
+				if !strings.Contains(path, "{"+p.Name+"}") {
+					t.Errorf("%s %s documenta el parámetro %q que no está en el path", rt.Method, path, p.Name)
+				}
+			}
+		}
+		for _, nombre := range enPath {
+			if !declarados[nombre] {
+				t.Errorf("%s %s no documenta el parámetro de path %q", rt.Method, path, nombre)
+			}
+		}
+	}
+
+	// Cada $ref del documento serializado tiene que existir en components.
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(string(data), -1) {
+		if _, ok := doc.Components.Schemas[m[1]]; !ok {
+			t.Errorf("$ref a %s no existe en components/schemas", m[1])
+		}
+	}
+}
+
+// esquemaPrueba son las tablas de tablas.go que usan los tests, sin las
+// columnas que no se consultan.
+var esquemaPrueba = []string{
+	`CREATE TABLE drivers (driver_number INTEGER PRIMARY KEY, first_name TEXT, last_name TEXT, name_acronym TEXT, team_name TEXT, country_code TEXT)`,
+	`CREATE TABLE sessions (session_key INTEGER PRIMARY KEY, session_name TEXT, session_type TEXT, location TEXT, country_name TEXT, year INTEGER, circuit_short_name TEXT, date_start TEXT, meeting_key INTEGER)`,
+	`CREATE TABLE laps (driver_number INTEGER, session_key INTEGER, lap_number INTEGER, lap_duration REAL, duration_sector_1 REAL, duration_sector_2 REAL, duration_sector_3 REAL, st_speed REAL, date_start TEXT, PRIMARY KEY(driver_number, session_key, lap_number))`,
+	`CREATE TABLE positions (driver_number INTEGER, session_key INTEGER, position INTEGER, date TEXT, PRIMARY KEY(driver_number, session_key))`,
+	`CREATE TABLE results (session_key INTEGER, driver_number INTEGER, position INTEGER, status TEXT, laps_completed INTEGER, gap_to_winner REAL, points INTEGER, fastest_lap INTEGER, PRIMARY KEY(session_key, driver_number))`,
+	`CREATE TABLE driver_sessions (session_key INTEGER, driver_number INTEGER, team_name TEXT, PRIMARY KEY(session_key, driver_number))`,
+}
+
+// pilotosPrueba son los pilotos que usan los tests.
+const pilotosPrueba = `INSERT INTO drivers VALUES (1, 'Max', 'Verstappen', 'VER', 'Red Bull Racing', 'NED'), (16, 'Charles', 'Leclerc', 'LEC', 'Ferrari', 'MON'), (44, 'Lewis', 'Hamilton', 'HAM', 'Mercedes', 'GBR')`
+
+// nuevaBase deja en db una base en memoria con esquemaPrueba y las filas de
+// cada test.
+func nuevaBase(t *testing.T, filas ...string) {
+	t.Helper()
+	var err error
+	db, err = sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cada conexion a :memory: es una base distinta.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	for _, q := range append(append([]string{}, esquemaPrueba...), filas...) {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// baseVueltasRapidas deja en db una base en memoria con dos carreras de 2024.
+// En la 1, Leclerc (16) y Hamilton (44) marcan 90.000 y Hamilton lo hace
+// primero; Verstappen (1) marca el mismo tiempo antes que los dos pero con
+// un sector en 0. En la 2 no hay vueltas validas. Con conTabla se crea
+// tambien session_fastest_lap, con una fila vieja para la carrera 2.
+func baseVueltasRapidas(t *testing.T, conTabla bool) {
+	filas := []string{
+		pilotosPrueba,
+		`INSERT INTO sessions (session_key, session_name, session_type, year, date_start) VALUES (1, 'Race', 'Race', 2024, '2024-03-02T15:00:00+00:00'), (2, 'Race', 'Race', 2024, '2024-03-09T17:00:00+00:00')`,
+		`INSERT INTO laps VALUES
+			(1, 1, 3, 90.0, 30.0, 0, 30.0, 320, '2024-03-02T15:05:00+00:00'),
+			(44, 1, 5, 90.0, 30.0, 30.0, 30.0, 318, '2024-03-02T15:08:00+00:00'),
+			(16, 1, 7, 90.0, 30.0, 30.0, 30.0, 319, '2024-03-02T15:11:00+00:00'),
+			(16, 1, 8, 91.5, 30.5, 30.5, 30.5, 317, '2024-03-02T15:12:30+00:00'),
+			(1, 2, 4, 88.0, 29.0, 0, 29.5, 321, '2024-03-09T17:06:00+00:00')`,
+	}
+	if conTabla {
+		filas = append(filas,
+			`CREATE TABLE session_fastest_lap (session_key INTEGER PRIMARY KEY, driver_number INTEGER, lap_number INTEGER, lap_duration REAL)`,
+			`INSERT INTO session_fastest_lap VALUES (2, 1, 4, 88.0)`,
+		)
+	}
+	nuevaBase(t, filas...)
+}
+
+func TestCalcularVueltaRapida(t *testing.T) {
+	baseVueltasRapidas(t, false)
+
+	vr, ok := calcularVueltaRapida(db, 1)
+	if !ok {
+		t.Fatal("la carrera 1 tiene vueltas validas")
+	}
+	if want := (vueltaRapidaSesion{piloto: 44, vuelta: 5, duracion: 90.0}); vr != want {
+		t.Errorf("calcularVueltaRapida(1) = %+v, se esperaba %+v", vr, want)
+	}
+	if vr, ok := calcularVueltaRapida(db, 2); ok {
+		t.Errorf("calcularVueltaRapida(2) = %+v, la carrera no tiene vueltas validas", vr)
+	}
+}
+
+func TestGuardarVueltasRapidas(t *testing.T) {
+	baseVueltasRapidas(t, true)
+	guardarVueltasRapidas()
+
+	guardadas := map[int]vueltaRapidaSesion{}
+	rows, err := db.Query("SELECT session_key, driver_number, lap_number, lap_duration FROM session_fastest_lap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var key int
+		var vr vueltaRapidaSesion
+		rows.Scan(&key, &vr.piloto, &vr.vuelta, &vr.duracion)
+		guardadas[key] = vr
+	}
+	rows.Close()
+
+	if want := (vueltaRapidaSesion{piloto: 44, vuelta: 5, duracion: 90.0}); guardadas[1] != want {
+		t.Errorf("session_fastest_lap de la carrera 1 = %+v, se esperaba %+v", guardadas[1], want)
+	}
+	if vr, ok := guardadas[2]; ok {
+		t.Errorf("quedo la fila vieja de la carrera 2: %+v", vr)
+	}
+}
+
+// El resumen y la metrica fastest_laps cuentan las vueltas rapidas aunque la
+// base no tenga session_fastest_lap, y dan lo mismo despues de
+// materializarla.
+func TestResumenVueltasRapidas(t *testing.T) {
+	want := []client.SeasonStat{{Position: 1, Driver: "Lewis Hamilton", Value: 1, TeamName: "Mercedes", CountryCode: "GBR"}}
+	for _, conTabla := range []bool{false, true} {
+		baseVueltasRapidas(t, conTabla)
+		if conTabla {
+			guardarVueltasRapidas()
+		}
+		got := consultarResumenTemporada(2024, false).FastestLaps
+		if len(got) != len(want) || got[0] != want[0] {
+			t.Errorf("con session_fastest_lap %v: FastestLaps = %+v, se esperaba %+v", conTabla, got, want)
+		}
+
+		m, _ := buscarMetrica("fastest_laps")
+		ranking, err := consultarEstadisticas(m, 2024, limitEstadisticas, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := ranking.Entries; len(e) != 1 || e[0].DriverNumber != 44 || e[0].Value != 1 || e[0].Position != 1 {
+			t.Errorf("con session_fastest_lap %v: ranking fastest_laps = %+v", conTabla, e)
+		}
+	}
+}
+
+// Victorias y podios salen de results sin los descalificados: Verstappen (1)
+// cruza primero en la carrera 1 pero queda DSQ. La carrera 2 no tiene
+// results y se cuenta desde positions.
+func TestVictoriasDesdeResults(t *testing.T) {
+	nuevaBase(t,
+		pilotosPrueba,
+		`INSERT INTO sessions (session_key, session_name, year) VALUES (1, 'Race', 2024), (2, 'Race', 2024)`,
+		`INSERT INTO positions (driver_number, session_key, position) VALUES (1, 1, 1), (44, 1, 2), (16, 1, 3), (1, 2, 1), (16, 2, 2), (44, 2, 3)`,
+		`INSERT INTO results (session_key, driver_number, position, status) VALUES (1, 44, 1, 'Finished'), (1, 16, 2, 'Finished'), (1, 1, 3, 'DSQ')`,
+	)
+
+	casos := []struct {
+		metrica string
+		want    map[int]float64
+	}{
+		{"wins", map[int]float64{44: 1, 1: 1}},
+		{"podiums", map[int]float64{44: 2, 16: 2, 1: 1}},
+	}
+	for _, c := range casos {
+		m, _ := buscarMetrica(c.metrica)
+		ranking, err := consultarEstadisticas(m, 2024, limitEstadisticas, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[int]float64{}
+		for _, e := range ranking.Entries {
+			got[e.DriverNumber] = e.Value
+		}
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%s = %v, se esperaba %v", c.metrica, got, c.want)
+		}
+	}
+
+	if cb := consultarCountback(2024); compararCountback(cb[1], []int{1}) != 0 || compararCountback(cb[44], []int{1, 0, 1}) != 0 {
+		t.Errorf("countback = %v", cb)
+	}
+	if w := consultarResumenTemporada(2024, false).Winners; len(w) != 2 || w[0].Value != 1 || w[1].Value != 1 {
+		t.Errorf("Winners = %+v", w)
+	}
+}
+
+func TestRankear(t *testing.T) {
+	// valores de los pilotos 1, 16, 44 y 63, en ese orden.
+	puestos := func(valores ...float64) []puesto {
+		var lista []puesto
+		for i, numero := range []int{1, 16, 44, 63} {
+			lista = append(lista, puesto{numero: numero, valor: valores[i]})
+		}
+		return lista
+	}
+	casos := []struct {
+		nombre    string
+		puestos   []puesto
+		countback map[int][]int
+		n         int
+		empates   bool
+		want      []puesto // numero y posicion
+	}{
+		{
+			nombre:  "empatados comparten la posicion",
+			puestos: puestos(10, 8, 8, 5),
+			n:       4,
+			want:    []puesto{{numero: 1, posicion: 1}, {numero: 16, posicion: 2}, {numero: 44, posicion: 2}, {numero: 63, posicion: 4}},
+		},
+		{
+			nombre:    "countback ordena a los empatados",
+			puestos:   puestos(10, 8, 8, 5),
+			countback: map[int][]int{16: {1, 2}, 44: {1, 3}},
+			n:         4,
+			want:      []puesto{{numero: 1, posicion: 1}, {numero: 44, posicion: 2}, {numero: 16, posicion: 2}, {numero: 63, posicion: 4}},
+		},
+		{
+			nombre:  "sin empates corta en n",
+			puestos: puestos(10, 8, 8, 5),
+			n:       2,
+			want:    []puesto{{numero: 1, posicion: 1}, {numero: 16, posicion: 2}},
+		},
+		{
+			nombre:  "con empates sigue hasta el ultimo empatado",
+			puestos: puestos(10, 8, 8, 5),
+			n:       2,
+			empates: true,
+			want:    []puesto{{numero: 1, posicion: 1}, {numero: 16, posicion: 2}, {numero: 44, posicion: 2}},
+		},
+		{
+			nombre:  "con empates no agrega si el corte no cae en un empate",
+			puestos: puestos(10, 8, 8, 5),
+			n:       3,
+			empates: true,
+			want:    []puesto{{numero: 1, posicion: 1}, {numero: 16, posicion: 2}, {numero: 44, posicion: 2}},
+		},
+	}
+	for _, c := range casos {
+		got := rankear(c.puestos, c.countback, c.n, c.empates)
+		ok := len(got) == len(c.want)
+		for i := 0; ok && i < len(got); i++ {
+			ok = got[i].numero == c.want[i].numero && got[i].posicion == c.want[i].posicion
+		}
+		if !ok {
+			t.Errorf("%s: rankear = %+v, se esperaba %+v", c.nombre, got, c.want)
+		}
+	}
+}
+
+// Con session_fastest_lap a medio cargar se cuentan las filas guardadas (la
+// carrera 2) y se calculan solo las carreras que faltan (la 1).
+func TestContarVueltasRapidasFaltantes(t *testing.T) {
+	baseVueltasRapidas(t, true)
+	conteo, err := contarVueltasRapidas(2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conteo) != 2 || conteo[1] != 1 || conteo[44] != 1 {
+		t.Errorf("contarVueltasRapidas(2024) = %v, se esperaba map[1:1 44:1]", conteo)
+	}
+}
+
+// Verstappen no tiene sector 2, pero sus sectores 1 y 3 siguen siendo los
+// morados. Leclerc es el unico con los tres sectores.
+func TestVueltaIdealSectoresSueltos(t *testing.T) {
+	nuevaBase(t,
+		pilotosPrueba,
+		`INSERT INTO laps (driver_number, session_key, lap_number, lap_duration, duration_sector_1, duration_sector_2, duration_sector_3) VALUES
+			(1, 1, 4, 88.0, 29.0, 0, 29.5),
+			(16, 1, 4, 91.0, 30.0, 30.5, 30.5)`,
+	)
+	ideal := consultarVueltaIdeal(1)
+	morados := []client.PurpleSector{
+		{DriverNumber: 1, Driver: "Max Verstappen", Duration: 29.0},
+		{DriverNumber: 16, Driver: "Charles Leclerc", Duration: 30.5},
+		{DriverNumber: 1, Driver: "Max Verstappen", Duration: 29.5},
+	}
+	for i, got := range []client.PurpleSector{ideal.Sector1, ideal.Sector2, ideal.Sector3} {
+		if got != morados[i] {
+			t.Errorf("sector %d = %+v, se esperaba %+v", i+1, got, morados[i])
+		}
+	}
+	if len(ideal.Drivers) != 1 || ideal.Drivers[0].DriverNumber != 16 {
+		t.Errorf("Drivers = %+v, solo Leclerc tiene los tres sectores", ideal.Drivers)
+	}
+}
+
+// Hamilton (44) no tiene vueltas en la carrera 2: aparece como DNS en el
+// detalle y no cuenta como carrera largada.
+func TestDetallePilotoDNS(t *testing.T) {
+	nuevaBase(t,
+		pilotosPrueba,
+		`INSERT INTO sessions (session_key, session_name, session_type, year, circuit_short_name) VALUES (1, 'Race', 'Race', 2024, 'Sakhir'), (2, 'Race', 'Race', 2024, 'Jeddah')`,
+		`INSERT INTO laps (driver_number, session_key, lap_number, lap_duration, duration_sector_1, duration_sector_2, duration_sector_3, st_speed) VALUES (44, 1, 1, 90.0, 30.0, 30.0, 30.0, 318)`,
+		`INSERT INTO positions (driver_number, session_key, position) VALUES (44, 1, 1), (44, 2, 20)`,
+		`INSERT INTO results (session_key, driver_number, position, status, points, fastest_lap) VALUES (1, 44, 1, 'Finished', 25, 1), (2, 44, 20, 'DNS', 0, 0)`,
+	)
+	d := consultarDetallePiloto(44)
+	if len(d.RaceResults) != 2 {
+		t.Fatalf("RaceResults = %+v, se esperaban las dos carreras", d.RaceResults)
+	}
+	if r := d.RaceResults[1]; r.SessionKey != 2 || r.Status != client.StatusDNS || r.MaxSpeed != 0 {
+		t.Errorf("carrera 2 = %+v", r)
+	}
+	if d.PerformanceSummary.RacesStarted != 1 {
+		t.Errorf("RacesStarted = %d, se esperaba 1", d.PerformanceSummary.RacesStarted)
+	}
+}
+
+// Leclerc (16) abandona la carrera 1 con Ferrari y despues pasa a Mercedes;
+// el abandono queda en Ferrari aunque drivers diga Mercedes.
+func TestFiabilidadEquipoPorCarrera(t *testing.T) {
+	nuevaBase(t,
+		`INSERT INTO drivers VALUES (16, 'Charles', 'Leclerc', 'LEC', 'Mercedes', 'MON'), (44, 'Lewis', 'Hamilton', 'HAM', 'Mercedes', 'GBR')`,
+		`INSERT INTO sessions (session_key, session_name, year) VALUES (1, 'Race', 2024), (2, 'Race', 2024)`,
+		`INSERT INTO driver_sessions VALUES (1, 16, 'Ferrari'), (1, 44, 'Mercedes'), (2, 16, 'Mercedes'), (2, 44, 'Mercedes')`,
+		`INSERT INTO results (session_key, driver_number, position, status) VALUES (1, 44, 1, 'Finished'), (1, 16, 2, 'DNF'), (2, 16, 1, 'Finished'), (2, 44, 2, 'Finished')`,
+	)
+	got := map[string][2]int{}
+	for _, e := range consultarFiabilidad(2024).Teams {
+		got[e.TeamName] = [2]int{e.Starts, e.DNFs}
+	}
+	want := map[string][2]int{"Ferrari": {1, 1}, "Mercedes": {3, 0}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("largadas y abandonos por equipo = %v, se esperaba %v", got, want)
+	}
+}
+
+// El sprint de la fecha 2 no tiene carrera cargada despues: sus puntos
+// quedan en una fecha propia y Leclerc (16) pasa a Verstappen (1).
+func TestTemporadaSprintAlFinal(t *testing.T) {
+	nuevaBase(t,
+		pilotosPrueba,
+		`INSERT INTO sessions (session_key, session_name, session_type, year, circuit_short_name, date_start) VALUES
+			(1, 'Race', 'Race', 2024, 'Sakhir', '2024-03-02T15:00:00+00:00'),
+			(2, 'Sprint', 'Race', 2024, 'Shanghai', '2024-04-20T03:00:00+00:00')`,
+		`INSERT INTO results VALUES
+			(1, 1, 1, 'Finished', 57, NULL, 25, 0), (1, 16, 2, 'Finished', 57, 5.0, 18, 0),
+			(2, 16, 1, 'Finished', 19, NULL, 8, 0), (2, 1, 9, 'Finished', 19, 30.0, 0, 0)`,
+	)
+	temporada, err := consultarTemporadaPiloto(1, 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(temporada.Rounds); n != 2 {
+		t.Fatalf("Rounds = %+v, se esperaban la carrera y el sprint", temporada.Rounds)
+	}
+	r := temporada.Rounds[1]
+	if r.SessionKey != 2 || r.CumulativePoints != 25 || r.ChampionshipPosition != 2 {
+		t.Errorf("fecha del sprint = %+v", r)
+	}
+	if temporada.Points != 25 || temporada.ChampionshipPosition != 2 {
+		t.Errorf("Points = %d, ChampionshipPosition = %d, se esperaba 25 y 2", temporada.Points, temporada.ChampionshipPosition)
+	}
+
+	leclerc, err := consultarTemporadaPiloto(16, 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leclerc.Points != 26 || leclerc.ChampionshipPosition != 1 {
+		t.Errorf("Leclerc: Points = %d, ChampionshipPosition = %d, se esperaba 26 y 1", leclerc.Points, leclerc.ChampionshipPosition)
+	}
+}
+
+// Verstappen (1) cruza primero pero queda DSQ: la referencia del ritmo es
+// Hamilton (44), el ganador de la clasificacion.
+func TestRitmoGanadorDeLaClasificacion(t *testing.T) {
+	nuevaBase(t,
+		pilotosPrueba,
+		`INSERT INTO positions (driver_number, session_key, position) VALUES (1, 1, 1), (44, 1, 2)`,
+		`INSERT INTO results (session_key, driver_number, position, status) VALUES (1, 44, 1, 'Finished'), (1, 1, 2, 'DSQ')`,
+		`INSERT INTO laps (driver_number, session_key, lap_number, lap_duration, duration_sector_1, duration_sector_2, duration_sector_3) VALUES
+			(1, 1, 2, 90.0, 30.0, 30.0, 30.0), (1, 1, 3, 90.0, 30.0, 30.0, 30.0),
+			(44, 1, 2, 91.0, 30.0, 30.0, 31.0), (44, 1, 3, 91.0, 30.0, 30.0, 31.0)`,
+	)
+	ritmo := consultarRitmo(1)
+	if ritmo.WinnerMedianLap == nil || *ritmo.WinnerMedianLap != 91.0 {
+		t.Fatalf("WinnerMedianLap = %v, se esperaba la mediana de Hamilton", ritmo.WinnerMedianLap)
+	}
+	for _, dp := range ritmo.Drivers {
+		if dp.DriverNumber == 1 && (dp.Position == nil || *dp.Position != 2) {
+			t.Errorf("posicion de Verstappen = %v, se esperaba 2", dp.Position)
+		}
+	}
+}
+
+// vueltasPrueba arma las filas de laps de un piloto en la sesion 1: n
+// vueltas seguidas de la misma duracion, desde las 15:00, con los tres
+// sectores.
+func vueltasPrueba(piloto, n int, duracion float64) string {
+	inicio := time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC)
+	var filas []string
+	for i := 0; i < n; i++ {
+		desde := inicio.Add(time.Duration(float64(i) * duracion * float64(time.Second)))
+		filas = append(filas, fmt.Sprintf("(%d, 1, %d, %g, 30, 30, %g, '%s')", piloto, i+1, duracion, duracion-60, desde.Format(time.RFC3339Nano)))
+	}
+	return "INSERT INTO laps (driver_number, session_key, lap_number, lap_duration, duration_sector_1, duration_sector_2, duration_sector_3, date_start) VALUES " + strings.Join(filas, ", ")
+}
+
+// Una carrera a 10 vueltas: Leclerc (16) termina detras del ganador, 55 queda
+// doblado, Hamilton (44) abandona en la 9 (el 90%) y se clasifica, 63
+// abandona en la 5 y 81 es descalificado.
+func TestCalcularClasificacion(t *testing.T) {
+	nuevaBase(t,
+		pilotosPrueba,
+		`INSERT INTO drivers VALUES (55, 'Carlos', 'Sainz', 'SAI', 'Ferrari', 'ESP'), (63, 'George', 'Russell', 'RUS', 'Mercedes', 'GBR'), (81, 'Oscar', 'Piastri', 'PIA', 'McLaren', 'AUS')`,
+		`INSERT INTO sessions (session_key, session_name, session_type, year) VALUES (1, 'Race', 'Race', 2024)`,
+		`INSERT INTO positions (driver_number, session_key, position) VALUES (1, 1, 1), (16, 1, 2), (81, 1, 3), (55, 1, 4), (44, 1, 5), (63, 1, 6)`,
+		`CREATE TABLE race_control (session_key INTEGER, driver_number INTEGER, message TEXT)`,
+		`INSERT INTO race_control VALUES (1, 81, 'CAR 81 (PIA) DISQUALIFIED FROM THE RACE')`,
+		vueltasPrueba(1, 10, 90),
+		vueltasPrueba(16, 10, 91),
+		vueltasPrueba(81, 10, 90.5),
+		vueltasPrueba(55, 9, 101),
+		vueltasPrueba(44, 9, 90.2),
+		vueltasPrueba(63, 5, 92),
+	)
+	type fila struct {
+		piloto  int
+		estado  string
+		vueltas int
+		puntos  int
+	}
+	want := []fila{
+		{1, client.StatusFinished, 10, 25 + puntoVueltaRapida},
+		{16, client.StatusFinished, 10, 18},
+		{55, "+1 Lap", 9, 15},
+		{44, client.StatusDNF, 9, 12},
+		{63, client.StatusDNF, 5, 0},
+		{81, client.StatusDSQ, 10, 0},
+	}
+
+	// guardarResultados la calcula y la guarda; consultarClasificacion la lee
+	// de results.
+	guardarResultados()
+	got := consultarClasificacion(1)
+	if len(got) != len(want) {
+		t.Fatalf("clasificacion = %+v", got)
+	}
+	for i, r := range got {
+		w := want[i]
+		if r.Position != i+1 || r.DriverNumber != w.piloto || r.Status != w.estado || r.LapsCompleted != w.vueltas || r.Points != w.puntos {
+			t.Errorf("P%d = %d %s %d vueltas %d puntos, se esperaba %+v", r.Position, r.DriverNumber, r.Status, r.LapsCompleted, r.Points, w)
+		}
+	}
+	if !got[0].FastestLap || got[0].GapToWinner != nil {
+		t.Errorf("ganador = %+v, se esperaba la vuelta rapida y sin diferencia", got[0])
+	}
+	if g := got[1].GapToWinner; g == nil || *g != 10 {
+		t.Errorf("diferencia de Leclerc = %v, se esperaba 10", g)
+	}
+}
+
+// trazaConstante son las muestras de una vuelta a velocidad constante, una
+// por segundo desde desdeMs.
+func trazaConstante(kmh uint16, segundos int, desdeMs uint32) []client.TelemetryPoint {
+	var muestras []muestraTelemetria
+	for i := 0; i <= segundos; i++ {
+		muestras = append(muestras, muestraTelemetria{ms: desdeMs + uint32(i)*1000, velocidad: kmh})
+	}
+	return reducirTelemetria(muestras, len(muestras))
+}
+
+func TestReducirTelemetriaDistancia(t *testing.T) {
+	// A 36 km/h son 10 m por segundo.
+	traza := trazaConstante(36, 10, 0)
+	for i, p := range traza {
+		if math.Abs(p.Distance-float64(i)*10) > 1e-9 {
+			t.Fatalf("distancia en %gs = %g, se esperaba %d", p.Time, p.Distance, i*10)
+		}
+	}
+	if reducida := reducirTelemetria([]muestraTelemetria{{ms: 0}, {ms: 500}, {ms: 1000}, {ms: 1500}}, 2); len(reducida) != 2 {
+		t.Errorf("reducirTelemetria a 2 puntos dio %d", len(reducida))
+	}
+}
+
+// A da 100 m en 10 s y B los mismos 100 m en 12 s, empezando a contar medio
+// segundo despues. Alineadas por distancia, B pierde 1 s a mitad de vuelta
+// y 2 s al final.
+func TestCompararVueltasAlineaPorDistancia(t *testing.T) {
+	a := trazaConstante(36, 10, 0)
+	b := trazaConstante(30, 12, 500)
+	cmp := compararVueltas(1, client.LapRef{}, client.LapRef{}, a, b, 3)
+	if cmp.LapLength != 100 || len(cmp.Points) != 3 {
+		t.Fatalf("LapLength = %g, %d puntos", cmp.LapLength, len(cmp.Points))
+	}
+	for i, want := range []struct{ distancia, delta float64 }{{0, 0}, {50, 1}, {100, 2}} {
+		p := cmp.Points[i]
+		if math.Abs(p.Distance-want.distancia) > 1e-9 || math.Abs(p.Delta-want.delta) > 1e-9 {
+			t.Errorf("punto %d = distancia %g delta %g, se esperaba %g y %g", i, p.Distance, p.Delta, want.distancia, want.delta)
+		}
+		if p.SpeedA != 36 || p.SpeedB != 30 {
+			t.Errorf("punto %d: velocidades %g y %g", i, p.SpeedA, p.SpeedB)
+		}
+	}
+
+	if vacia := compararVueltas(1, client.LapRef{}, client.LapRef{}, a, nil, 3); len(vacia.Points) != 0 {
+		t.Errorf("sin muestras de B hay %d puntos", len(vacia.Points))
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	db, err := sql.Open("sqlite3", "proxy.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	createTables(db)
+	log.Println("Base de datos creada correctamente.")
+}
+
+func createTables(db *sql.DB) {
+	queries := []string{
+		// Tabla de pilotos
+		`CREATE TABLE IF NOT EXISTS drivers (
+			driver_number INTEGER PRIMARY KEY,
+			first_name TEXT,
+			last_name TEXT,
+			name_acronym TEXT,
+			team_name TEXT,
+			country_code TEXT
+		);`,
+
+		// Tabla de sesiones
+		`CREATE TABLE IF NOT EXISTS sessions (
+			session_key INTEGER PRIMARY KEY,
+			session_name TEXT,
+			session_type TEXT,
+			location TEXT,
+			country_name TEXT,
+			year INTEGER,
+			circuit_short_name TEXT,
+			date_start TEXT,
+			meeting_key INTEGER
+		);`,
+
+		// Tabla de grandes premios (fines de semana), que agrupan las sesiones
+		`CREATE TABLE IF NOT EXISTS meetings (
+			meeting_key INTEGER PRIMARY KEY,
+			meeting_name TEXT,
+			meeting_official_name TEXT,
+			location TEXT,
+			country_name TEXT,
+			circuit_short_name TEXT,
+			date_start TEXT,
+			year INTEGER
+		);`,
+
+		// Tabla de equipos por sesion (un piloto puede cambiar de equipo en el año)
+		`CREATE TABLE IF NOT EXISTS driver_sessions (
+			session_key INTEGER,
+			driver_number INTEGER,
+			team_name TEXT,
+			PRIMARY KEY(session_key, driver_number)
+		);`,
+
+		// Tabla de posiciones
+		`CREATE TABLE IF NOT EXISTS positions (
+			driver_number INTEGER,
+			session_key INTEGER,
+			position INTEGER,
+			date TEXT,
+			PRIMARY KEY(driver_number, session_key)
+		);`,
+
+		// Tabla de historial de posiciones (cada cambio de posicion que manda OpenF1)
+		`CREATE TABLE IF NOT EXISTS position_history (
+			session_key INTEGER,
+			driver_number INTEGER,
+			date TEXT,
+			position INTEGER,
+			PRIMARY KEY(session_key, driver_number, date)
+		);`,
+
+		// Tabla de vueltas
+		`CREATE TABLE IF NOT EXISTS laps (
+			driver_number INTEGER,
+			session_key INTEGER,
+			lap_number INTEGER,
+			lap_duration REAL,
+			duration_sector_1 REAL,
+			duration_sector_2 REAL,
+			duration_sector_3 REAL,
+			st_speed REAL,
+			date_start TEXT,
+			PRIMARY KEY(driver_number, session_key, lap_number)
+		);`,
+
+		// Tabla de stints (neumaticos usados por cada piloto)
+		`CREATE TABLE IF NOT EXISTS stints (
+			driver_number INTEGER,
+			session_key INTEGER,
+			stint_number INTEGER,
+			compound TEXT,
+			lap_start INTEGER,
+			lap_end INTEGER,
+			tyre_age_at_start INTEGER,
+			PRIMARY KEY(driver_number, session_key, stint_number)
+		);`,
+
+		// Tabla de paradas en boxes
+		`CREATE TABLE IF NOT EXISTS pit_stops (
+			driver_number INTEGER,
+			session_key INTEGER,
+			lap_number INTEGER,
+			pit_duration REAL,
+			date TEXT,
+			PRIMARY KEY(driver_number, session_key, lap_number)
+		);`,
+
+		// Tabla de clima (una medicion por minuto aprox.)
+		`CREATE TABLE IF NOT EXISTS weather (
+			session_key INTEGER,
+			date TEXT,
+			air_temperature REAL,
+			track_temperature REAL,
+			humidity REAL,
+			pressure REAL,
+			rainfall INTEGER,
+			wind_direction INTEGER,
+			wind_speed REAL,
+			PRIMARY KEY(session_key, date)
+		);`,
+
+		// Tabla de mensajes de direccion de carrera (banderas, safety car, sanciones)
+		`CREATE TABLE IF NOT EXISTS race_control (
+			session_key INTEGER,
+			date TEXT,
+			category TEXT,
+			flag TEXT,
+			scope TEXT,
+			sector INTEGER,
+			driver_number INTEGER,
+			lap_number INTEGER,
+			message TEXT,
+			PRIMARY KEY(session_key, date, message)
+		);`,
+
+		// Tabla de intervalos (diferencia al lider y al auto de adelante).
+		// gap_to_leader e interval son NULL cuando el piloto esta a una o mas
+		// vueltas; en ese caso laps_behind dice a cuantas.
+		`CREATE TABLE IF NOT EXISTS intervals (
+			session_key INTEGER,
+			driver_number INTEGER,
+			date TEXT,
+			gap_to_leader REAL,
+			interval REAL,
+			laps_behind INTEGER,
+			PRIMARY KEY(session_key, driver_number, date)
+		);`,
+
+		// Tabla de telemetria. Una fila por vuelta con las muestras
+		// empaquetadas en data (ver codificarTelemetria en cmd/server).
+		`CREATE TABLE IF NOT EXISTS car_data (
+			session_key INTEGER,
+			driver_number INTEGER,
+			lap_number INTEGER,
+			samples INTEGER,
+			max_speed REAL,
+			data BLOB,
+			PRIMARY KEY(session_key, driver_number, lap_number)
+		);`,
+
+		// Tabla de vueltas rapidas (la vuelta valida mas rapida de cada sesion)
+		`CREATE TABLE IF NOT EXISTS session_fastest_lap (
+			session_key INTEGER PRIMARY KEY,
+			driver_number INTEGER,
+			lap_number INTEGER,
+			lap_duration REAL
+		);`,
+
+		// Tabla de lideres de cada vuelta de carrera
+		`CREATE TABLE IF NOT EXISTS lap_leaders (
+			session_key INTEGER,
+			lap_number INTEGER,
+			driver_number INTEGER,
+			PRIMARY KEY(session_key, lap_number)
+		);`,
+
+		// Tabla de resultados de carrera (clasificacion con estado de cada piloto)
+		`CREATE TABLE IF NOT EXISTS results (
+			session_key INTEGER,
+			driver_number INTEGER,
+			position INTEGER,
+			status TEXT,
+			laps_completed INTEGER,
+			gap_to_winner REAL,
+			points INTEGER,
+			fastest_lap INTEGER,
+			PRIMARY KEY(session_key, driver_number)
+		);`,
+	}
+
+	for _, q := range queries {
+		_, err := db.Exec(q)
+		if err != nil {
+			log.Fatalf("Error creando tabla: %v", err)
+		}
+	}
+
+	// Columnas agregadas despues de crear la tabla; en una base nueva ya vienen
+	// en el CREATE TABLE.
+	agregarColumna(db, "sessions", "meeting_key", "INTEGER")
+}
+
+// agregarColumna agrega la columna a una tabla existente si todavia no la
+// tiene. SQLite no acepta ADD COLUMN IF NOT EXISTS.
+func agregarColumna(db *sql.DB, tabla, columna, tipo string) {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", tabla)
+	if err != nil {
+		log.Fatalf("Error leyendo columnas de %s: %v", tabla, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var nombre string
+		rows.Scan(&nombre)
+		if nombre == columna {
+			return
+		}
+	}
+	if _, err := db.Exec("ALTER TABLE " + tabla + " ADD COLUMN " + columna + " " + tipo); err != nil {
+		log.Fatalf("Error agregando %s.%s: %v", tabla, columna, err)
+	}
+}
@@ -0,0 +1,344 @@
+// Package openapi genera un documento OpenAPI 3 a partir de las rutas del
+// servidor y de los tipos Go que devuelven sus handlers.
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Parametro es un parametro de query o de path de una operacion.
+type Parametro struct {
+	Nombre      string
+	En          string // "query" o "path"
+	Tipo        string // "integer", "number", "string" o "boolean"
+	Descripcion string
+	Requerido   bool
+}
+
+// Operacion describe un endpoint. Path usa el formato de gin (/drivers/:id)
+// y Respuesta es un valor del tipo que el handler serializa como JSON.
+type Operacion struct {
+	Metodo     string
+	Path       string
+	Resumen    string
+	Tags       []string
+	Parametros []Parametro
+	Respuesta  interface{}
+}
+
+type Documento struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]operacion `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Esquema `json:"schemas"`
+}
+
+type operacion struct {
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	OperationID string               `json:"operationId"`
+	Parameters  []parametro          `json:"parameters,omitempty"`
+	Responses   map[string]respuesta `json:"responses"`
+}
+
+type parametro struct {
+	Name        string   `json:"name"`
+	In          string   `json:"in"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required"`
+	Schema      *Esquema `json:"schema"`
+}
+
+type respuesta struct {
+	Description string                `json:"description"`
+	Content     map[string]mediaTypes `json:"content,omitempty"`
+}
+
+type mediaTypes struct {
+	Schema *Esquema `json:"schema"`
+}
+
+// Esquema es el subconjunto de JSON Schema que usa OpenAPI 3.0.
+type Esquema struct {
+	Ref                  string              `json:"$ref,omitempty"`
+	Type                 string              `json:"type,omitempty"`
+	Format               string              `json:"format,omitempty"`
+	Nullable             bool                `json:"nullable,omitempty"`
+	Properties           map[string]*Esquema `json:"properties,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	Items                *Esquema            `json:"items,omitempty"`
+	AllOf                []*Esquema          `json:"allOf,omitempty"`
+	AdditionalProperties *Esquema            `json:"additionalProperties,omitempty"`
+}
+
+// Error es el cuerpo que devuelven los handlers cuando una peticion falla.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Generar arma el documento y valida que cada operacion sea consistente: sin
+// rutas duplicadas y con todos los parametros de path declarados en el path.
+func Generar(titulo, version string, ops []Operacion) (*Documento, error) {
+	g := &generador{
+		esquemas: map[string]*Esquema{},
+		nombres:  map[reflect.Type]string{},
+	}
+	doc := &Documento{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: titulo, Version: version},
+		Paths:   map[string]map[string]operacion{},
+	}
+	errorRef := g.esquema(reflect.TypeOf(Error{}))
+
+	for _, op := range ops {
+		path, enPath := convertirPath(op.Path)
+		metodo := strings.ToLower(op.Metodo)
+		if _, existe := doc.Paths[path][metodo]; existe {
+			return nil, fmt.Errorf("openapi: %s %s está duplicada", op.Metodo, op.Path)
+		}
+
+		var params []parametro
+		declarados := map[string]bool{}
+		for _, p := range op.Parametros {
+			if p.En == "path" && !contiene(enPath, p.Nombre) {
+				return nil, fmt.Errorf("openapi: %s %s declara el parámetro %q que no está en el path", op.Metodo, op.Path, p.Nombre)
+			}
+			declarados[p.Nombre] = true
+			params = append(params, parametro{
+				Name:        p.Nombre,
+				In:          p.En,
+				Description: p.Descripcion,
+				Required:    p.Requerido || p.En == "path",
+				Schema:      &Esquema{Type: p.Tipo},
+			})
+		}
+		for _, nombre := range enPath {
+			if !declarados[nombre] {
+				params = append(params, parametro{Name: nombre, In: "path", Required: true, Schema: &Esquema{Type: "string"}})
+			}
+		}
+
+		respuestas := map[string]respuesta{
+			"default": {Description: "Error", Content: map[string]mediaTypes{"application/json": {Schema: errorRef}}},
+		}
+		ok := respuesta{Description: "OK"}
+		if op.Respuesta != nil {
+			ok.Content = map[string]mediaTypes{"application/json": {Schema: g.esquema(reflect.TypeOf(op.Respuesta))}}
+		}
+		respuestas["200"] = ok
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]operacion{}
+		}
+		doc.Paths[path][metodo] = operacion{
+			Summary:     op.Resumen,
+			Tags:        op.Tags,
+			OperationID: idOperacion(metodo, op.Path),
+			Parameters:  params,
+			Responses:   respuestas,
+		}
+	}
+	doc.Components.Schemas = g.esquemas
+	return doc, doc.Validar()
+}
+
+// Validar revisa que cada $ref apunte a un esquema de components/schemas y
+// que los parametros de path de cada operacion coincidan con los del path.
+func (d *Documento) Validar() error {
+	for path, metodos := range d.Paths {
+		_, enPath := convertirPath(strings.NewReplacer("{", ":", "}", "").Replace(path))
+		for metodo, op := range metodos {
+			declarados := map[string]bool{}
+			for _, p := range op.Parameters {
+				if p.In != "path" {
+					continue
+				}
+				if !contiene(enPath, p.Name) {
+					return fmt.Errorf("openapi: %s %s declara el parámetro %q que no está en el path", metodo, path, p.Name)
+				}
+				declarados[p.Name] = true
+			}
+			for _, nombre := range enPath {
+				if !declarados[nombre] {
+					return fmt.Errorf("openapi: %s %s no declara el parámetro de path %q", metodo, path, nombre)
+				}
+			}
+			for codigo, r := range op.Responses {
+				for _, m := range r.Content {
+					if err := d.validarRefs(m.Schema); err != nil {
+						return fmt.Errorf("openapi: %s %s respuesta %s: %w", metodo, path, codigo, err)
+					}
+				}
+			}
+		}
+	}
+	for nombre, e := range d.Components.Schemas {
+		if err := d.validarRefs(e); err != nil {
+			return fmt.Errorf("openapi: esquema %s: %w", nombre, err)
+		}
+	}
+	return nil
+}
+
+func (d *Documento) validarRefs(e *Esquema) error {
+	if e == nil {
+		return nil
+	}
+	if e.Ref != "" {
+		nombre := strings.TrimPrefix(e.Ref, "#/components/schemas/")
+		if _, ok := d.Components.Schemas[nombre]; !ok || nombre == e.Ref {
+			return fmt.Errorf("$ref %s no existe", e.Ref)
+		}
+	}
+	for _, p := range e.Properties {
+		if err := d.validarRefs(p); err != nil {
+			return err
+		}
+	}
+	if err := d.validarRefs(e.Items); err != nil {
+		return err
+	}
+	for _, a := range e.AllOf {
+		if err := d.validarRefs(a); err != nil {
+			return err
+		}
+	}
+	return d.validarRefs(e.AdditionalProperties)
+}
+
+type generador struct {
+	esquemas map[string]*Esquema
+	nombres  map[reflect.Type]string
+}
+
+// esquema devuelve el esquema de t. Los structs con nombre se registran en
+// components/schemas y se referencian con $ref. Los punteros son nullable;
+// los slices no, porque los handlers siempre mandan [] y nunca null.
+func (g *generador) esquema(t reflect.Type) *Esquema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		e := g.esquema(t.Elem())
+		if e.Ref != "" {
+			// En OpenAPI 3.0 nullable se ignora junto a $ref, asi que la
+			// referencia va dentro de allOf.
+			return &Esquema{AllOf: []*Esquema{e}, Nullable: true}
+		}
+		e.Nullable = true
+		return e
+	case reflect.Bool:
+		return &Esquema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Esquema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Esquema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Esquema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Esquema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Esquema{Type: "array", Items: g.esquema(t.Elem())}
+	case reflect.Map:
+		return &Esquema{Type: "object", AdditionalProperties: g.esquema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.objeto(t)
+		}
+		nombre, ok := g.nombres[t]
+		if !ok {
+			nombre = g.nombreLibre(t.Name())
+			g.nombres[t] = nombre
+			// Se reserva antes de recorrer los campos para soportar tipos recursivos.
+			g.esquemas[nombre] = &Esquema{}
+			*g.esquemas[nombre] = *g.objeto(t)
+		}
+		return &Esquema{Ref: "#/components/schemas/" + nombre}
+	}
+	// interface{} y cualquier otro tipo se documentan como valor libre.
+	return &Esquema{}
+}
+
+func (g *generador) objeto(t reflect.Type) *Esquema {
+	e := &Esquema{Type: "object", Properties: map[string]*Esquema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		nombre, opciones, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if nombre == "-" {
+			continue
+		}
+		if f.Anonymous && nombre == "" && f.Type.Kind() == reflect.Struct {
+			embebido := g.objeto(f.Type)
+			for k, v := range embebido.Properties {
+				e.Properties[k] = v
+			}
+			e.Required = append(e.Required, embebido.Required...)
+			continue
+		}
+		if nombre == "" {
+			nombre = f.Name
+		}
+		e.Properties[nombre] = g.esquema(f.Type)
+		if !strings.Contains(opciones, "omitempty") {
+			e.Required = append(e.Required, nombre)
+		}
+	}
+	return e
+}
+
+// nombreLibre evita que dos tipos distintos con el mismo nombre (por ejemplo
+// tipos locales de distintos handlers) se pisen en components/schemas.
+func (g *generador) nombreLibre(base string) string {
+	nombre := base
+	for i := 2; ; i++ {
+		if _, usado := g.esquemas[nombre]; !usado {
+			return nombre
+		}
+		nombre = fmt.Sprintf("%s%d", base, i)
+	}
+}
+
+// convertirPath pasa /drivers/:id a /drivers/{id} y devuelve los parametros.
+func convertirPath(path string) (string, []string) {
+	var params []string
+	partes := strings.Split(path, "/")
+	for i, p := range partes {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			params = append(params, p[1:])
+			partes[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(partes, "/"), params
+}
+
+func idOperacion(metodo, path string) string {
+	id := metodo
+	for _, p := range strings.Split(path, "/") {
+		p = strings.Trim(p, ":*{}")
+		for _, palabra := range strings.FieldsFunc(p, func(r rune) bool { return r == '-' || r == '_' }) {
+			id += strings.ToUpper(palabra[:1]) + palabra[1:]
+		}
+	}
+	return id
+}
+
+func contiene(lista []string, valor string) bool {
+	for _, v := range lista {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+type punto struct {
+	X float64 `json:"x"`
+}
+
+type traza struct {
+	Nombre  string   `json:"nombre"`
+	Puntos  []punto  `json:"puntos"`
+	Mejor   *punto   `json:"mejor"`
+	Vecinos []*traza `json:"vecinos"`
+}
+
+func TestGenerarResuelveRefs(t *testing.T) {
+	doc, err := Generar("test", "1", []Operacion{
+		{Metodo: "GET", Path: "/trazas/:id", Respuesta: traza{}},
+		{Metodo: "GET", Path: "/trazas/:id/puntos/:n", Parametros: []Parametro{{Nombre: "n", En: "path", Tipo: "integer"}}, Respuesta: []punto{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, nombre := range []string{"traza", "punto", "Error"} {
+		if _, ok := doc.Components.Schemas[nombre]; !ok {
+			t.Errorf("falta el esquema %s", nombre)
+		}
+	}
+	op := doc.Paths["/trazas/{id}/puntos/{n}"]["get"]
+	if len(op.Parameters) != 2 {
+		t.Fatalf("parámetros = %+v, se esperaban id y n", op.Parameters)
+	}
+	for _, p := range op.Parameters {
+		if p.In != "path" || !p.Required {
+			t.Errorf("%s debería ser un parámetro de path obligatorio", p.Name)
+		}
+	}
+}
+
+// Un puntero a struct es una referencia nullable; un slice no es nullable.
+func TestGenerarNullable(t *testing.T) {
+	doc, err := Generar("test", "1", []Operacion{{Metodo: "GET", Path: "/trazas", Respuesta: traza{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := doc.Components.Schemas["traza"].Properties
+	mejor := props["mejor"]
+	if !mejor.Nullable || mejor.Ref != "" || len(mejor.AllOf) != 1 || mejor.AllOf[0].Ref != "#/components/schemas/punto" {
+		t.Errorf("mejor = %+v, se esperaba allOf con la ref a punto y nullable", mejor)
+	}
+	if props["puntos"].Nullable {
+		t.Error("puntos no debería ser nullable")
+	}
+	if v := props["vecinos"]; v.Nullable || !v.Items.Nullable || len(v.Items.AllOf) != 1 {
+		t.Errorf("vecinos = %+v, se esperaba un array de referencias nullable", v)
+	}
+}
+
+func TestGenerarRechazaOperacionesInvalidas(t *testing.T) {
+	casos := map[string][]Operacion{
+		"duplicada": {
+			{Metodo: "GET", Path: "/a"},
+			{Metodo: "GET", Path: "/a"},
+		},
+		"no está en el path": {
+			{Metodo: "GET", Path: "/a", Parametros: []Parametro{{Nombre: "id", En: "path", Tipo: "integer"}}},
+		},
+	}
+	for esperado, ops := range casos {
+		if _, err := Generar("test", "1", ops); err == nil || !strings.Contains(err.Error(), esperado) {
+			t.Errorf("error = %v, se esperaba uno con %q", err, esperado)
+		}
+	}
+}
+
+func TestValidarDetectaRefRota(t *testing.T) {
+	doc, err := Generar("test", "1", []Operacion{{Metodo: "GET", Path: "/trazas", Respuesta: traza{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(doc.Components.Schemas, "punto")
+	if err := doc.Validar(); err == nil || !strings.Contains(err.Error(), "punto") {
+		t.Errorf("error = %v, se esperaba la ref a punto rota", err)
+	}
+}
+
+func TestValidarDetectaParametroDePathFaltante(t *testing.T) {
+	doc, err := Generar("test", "1", []Operacion{{Metodo: "GET", Path: "/trazas/:id"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := doc.Paths["/trazas/{id}"]["get"]
+	op.Parameters = nil
+	doc.Paths["/trazas/{id}"]["get"] = op
+	if err := doc.Validar(); err == nil || !strings.Contains(err.Error(), "id") {
+		t.Errorf("error = %v, se esperaba el parámetro id faltante", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package render imprime los resultados del cliente como tabla, JSON, CSV o
+// Markdown.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Formato string
+
+const (
+	Table    Formato = "table"
+	JSON     Formato = "json"
+	CSV      Formato = "csv"
+	Markdown Formato = "markdown"
+)
+
+var Formatos = []Formato{Table, JSON, CSV, Markdown}
+
+func ParseFormato(s string) (Formato, error) {
+	for _, f := range Formatos {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("formato %q no soportado (table, json, csv o markdown)", s)
+}
+
+// Tabla es una seccion de la salida. Un comando puede devolver varias, por
+// ejemplo el detalle de carrera tiene resultados, vuelta rapida y velocidad.
+type Tabla struct {
+	Titulo   string
+	Columnas []string
+	Filas    [][]string
+}
+
+// Escribir imprime en el formato pedido. En JSON se serializa datos tal cual
+// (la respuesta tipada del server); el resto de formatos usa las tablas.
+func Escribir(w io.Writer, f Formato, datos interface{}, tablas ...Tabla) error {
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(datos)
+	case CSV:
+		cw := csv.NewWriter(w)
+		for i, t := range tablas {
+			if i > 0 {
+				cw.Write(nil)
+			}
+			cw.Write(t.Columnas)
+			cw.WriteAll(t.Filas)
+		}
+		cw.Flush()
+		return cw.Error()
+	case Markdown:
+		for i, t := range tablas {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			escribirMarkdown(w, t)
+		}
+		return nil
+	default:
+		for i, t := range tablas {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			escribirTabla(w, t)
+		}
+		return nil
+	}
+}
+
+func escribirTabla(w io.Writer, t Tabla) {
+	anchos := make([]int, len(t.Columnas))
+	for i, c := range t.Columnas {
+		anchos[i] = Ancho(c)
+	}
+	for _, fila := range t.Filas {
+		for i, celda := range fila {
+			if i < len(anchos) && Ancho(celda) > anchos[i] {
+				anchos[i] = Ancho(celda)
+			}
+		}
+	}
+
+	borde := "+"
+	for _, a := range anchos {
+		borde += strings.Repeat("-", a+2) + "+"
+	}
+	linea := func(celdas []string, alinearNumeros bool) {
+		var b strings.Builder
+		b.WriteString("|")
+		for i, a := range anchos {
+			var celda string
+			if i < len(celdas) {
+				celda = celdas[i]
+			}
+			relleno := strings.Repeat(" ", a-Ancho(celda))
+			if alinearNumeros && esNumero(celda) {
+				b.WriteString(" " + relleno + celda + " |")
+			} else {
+				b.WriteString(" " + celda + relleno + " |")
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+
+	if t.Titulo != "" {
+		fmt.Fprintln(w, t.Titulo)
+	}
+	fmt.Fprintln(w, borde)
+	linea(t.Columnas, false)
+	fmt.Fprintln(w, borde)
+	for _, fila := range t.Filas {
+		linea(fila, true)
+	}
+	fmt.Fprintln(w, borde)
+}
+
+func escribirMarkdown(w io.Writer, t Tabla) {
+	escapar := func(celdas []string) string {
+		partes := make([]string, len(celdas))
+		for i, c := range celdas {
+			partes[i] = strings.ReplaceAll(c, "|", `\|`)
+		}
+		return "| " + strings.Join(partes, " | ") + " |"
+	}
+	if t.Titulo != "" {
+		fmt.Fprintf(w, "### %s\n\n", t.Titulo)
+	}
+	fmt.Fprintln(w, escapar(t.Columnas))
+	sep := make([]string, len(t.Columnas))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	fmt.Fprintln(w, "|"+strings.Join(sep, "|")+"|")
+	for _, fila := range t.Filas {
+		fmt.Fprintln(w, escapar(fila))
+	}
+}
+
+// Ancho es la cantidad de columnas que ocupa s en la terminal. Se cuentan
+// runas y no bytes, y se ignoran las marcas combinantes, para que "País"
+// ocupe 4 columnas escrito con "í" o con "i" + acento.
+func Ancho(s string) int {
+	n := 0
+	for _, r := range s {
+		if !unicode.Is(unicode.Mn, r) {
+			n++
+		}
+	}
+	if n == 0 && s != "" {
+		return utf8.RuneCountInString(s)
+	}
+	return n
+}
+
+// barras son los niveles de Sparkline, de menor a mayor.
+var barras = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline dibuja los valores como una fila de barras escaladas entre el
+// minimo y el maximo. Los valores NaN (datos faltantes) quedan en blanco.
+func Sparkline(valores []float64) string {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range valores {
+		if !math.IsNaN(v) {
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+	}
+	var b strings.Builder
+	for _, v := range valores {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case max == min:
+			b.WriteRune(barras[len(barras)/2])
+		default:
+			b.WriteRune(barras[int(math.Round((v-min)/(max-min)*float64(len(barras)-1)))])
+		}
+	}
+	return b.String()
+}
+
+func esNumero(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAncho(t *testing.T) {
+	casos := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"Pais", 4},
+		{"País", 4},       // í precompuesta
+		{"Pai\u0301s", 4}, // i + acento combinante
+		{"Ñu\u0303", 2},   // mezcla de las dos
+		{"\u0301", 1},     // solo una marca combinante
+	}
+	for _, c := range casos {
+		if got := Ancho(c.s); got != c.want {
+			t.Errorf("Ancho(%q) = %d, se esperaba %d", c.s, got, c.want)
+		}
+	}
+}
+
+// Las columnas quedan alineadas aunque las celdas tengan acentos escritos de
+// las dos formas.
+func TestEscribirTablaAcentos(t *testing.T) {
+	tabla := Tabla{
+		Columnas: []string{"País", "Puntos"},
+		Filas: [][]string{
+			{"México", "25"},       // é precompuesta
+			{"Me\u0301xico", "18"}, // e + acento combinante
+			{"Japón", "7"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := Escribir(&buf, Table, nil, tabla); err != nil {
+		t.Fatal(err)
+	}
+	lineas := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lineas) != 7 {
+		t.Fatalf("la tabla tiene %d lineas:\n%s", len(lineas), buf.String())
+	}
+	for _, l := range lineas {
+		if Ancho(l) != Ancho(lineas[0]) {
+			t.Errorf("linea desalineada:\n%s", buf.String())
+			break
+		}
+	}
+	if want := "| Japón  |      7 |"; lineas[5] != want {
+		t.Errorf("fila = %q, se esperaba %q", lineas[5], want)
+	}
+}
+
+func TestEscribirMarkdown(t *testing.T) {
+	tabla := Tabla{
+		Titulo:   "Resultados",
+		Columnas: []string{"Piloto", "Estado"},
+		Filas:    [][]string{{"Max Verstappen", "Finished"}, {"Lewis Hamilton", "DNF | motor"}},
+	}
+	var buf bytes.Buffer
+	if err := Escribir(&buf, Markdown, nil, tabla); err != nil {
+		t.Fatal(err)
+	}
+	want := "### Resultados\n\n" +
+		"| Piloto | Estado |\n" +
+		"|---|---|\n" +
+		"| Max Verstappen | Finished |\n" +
+		"| Lewis Hamilton | DNF \\| motor |\n"
+	if buf.String() != want {
+		t.Errorf("markdown =\n%s\nse esperaba\n%s", buf.String(), want)
+	}
+}
+
+// Varias tablas en CSV van separadas por una linea vacia.
+func TestEscribirCSV(t *testing.T) {
+	tablas := []Tabla{
+		{Columnas: []string{"País", "Puntos"}, Filas: [][]string{{"Japón", "7"}, {"Reino Unido, GB", "18"}}},
+		{Columnas: []string{"Vuelta"}, Filas: [][]string{{"1:30.5"}}},
+	}
+	var buf bytes.Buffer
+	if err := Escribir(&buf, CSV, nil, tablas...); err != nil {
+		t.Fatal(err)
+	}
+	want := "País,Puntos\nJapón,7\n\"Reino Unido, GB\",18\n\nVuelta\n1:30.5\n"
+	if buf.String() != want {
+		t.Errorf("csv = %q, se esperaba %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,8 @@
+package tui
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlLeerTermios     = unix.TCGETS
+	ioctlEscribirTermios = unix.TCSETS
+)
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+func modoCrudo(f *os.File) (func(), error) {
+	return nil, errors.New("la interfaz de pantalla completa no está disponible en este sistema")
+}
+
+func esTerminal(f *os.File) bool { return false }
+
+func tamano(f *os.File) (ancho, alto int) { return 80, 24 }
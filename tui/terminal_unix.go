@@ -0,0 +1,44 @@
+//go:build linux || darwin
+
+package tui
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// modoCrudo deja la terminal sin eco ni buffer de linea, para leer cada
+// tecla apenas se presiona. La funcion devuelta restaura el estado anterior.
+func modoCrudo(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+	original, err := unix.IoctlGetTermios(fd, ioctlLeerTermios)
+	if err != nil {
+		return nil, err
+	}
+	crudo := *original
+	crudo.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	crudo.Oflag &^= unix.OPOST
+	crudo.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	crudo.Cflag &^= unix.CSIZE | unix.PARENB
+	crudo.Cflag |= unix.CS8
+	crudo.Cc[unix.VMIN] = 1
+	crudo.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlEscribirTermios, &crudo); err != nil {
+		return nil, err
+	}
+	return func() { unix.IoctlSetTermios(fd, ioctlEscribirTermios, original) }, nil
+}
+
+func esTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), ioctlLeerTermios)
+	return err == nil
+}
+
+func tamano(f *os.File) (ancho, alto int) {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
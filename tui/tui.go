@@ -0,0 +1,466 @@
+// Package tui es la interfaz de pantalla completa del cliente: listas de
+// pilotos y carreras, busqueda mientras se escribe y navegacion entre el
+// detalle de una carrera y el de sus pilotos.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"tarea1sd/client"
+	"tarea1sd/render"
+)
+
+// ErrNoDisponible indica que la terminal no soporta la interfaz (no es una
+// terminal, o TERM=dumb) y que hay que usar el menu de texto.
+var ErrNoDisponible = errors.New("la terminal no soporta la interfaz de pantalla completa")
+
+type item struct {
+	texto string
+	abrir func() (*pantalla, error) // nil si la fila no tiene detalle
+}
+
+type pantalla struct {
+	titulo   string
+	info     []string // lineas fijas sobre la lista
+	cabecera string
+	items    []item
+	filtro   string
+	sel      int
+	desde    int // primera fila visible
+}
+
+func (p *pantalla) visibles() []item {
+	if p.filtro == "" {
+		return p.items
+	}
+	buscado := normalizar(p.filtro)
+	var lista []item
+	for _, it := range p.items {
+		if strings.Contains(normalizar(it.texto), buscado) {
+			lista = append(lista, it)
+		}
+	}
+	return lista
+}
+
+type app struct {
+	api      *client.Client
+	pestanas []*pantalla // pilotos y carreras
+	actual   int
+	pila     []*pantalla // pantallas de detalle abiertas
+	mensaje  string
+	out      *bufio.Writer
+}
+
+// Ejecutar abre la interfaz y vuelve cuando el usuario sale con Ctrl+C.
+func Ejecutar(api *client.Client) error {
+	if os.Getenv("TERM") == "dumb" || !esTerminal(os.Stdin) || !esTerminal(os.Stdout) {
+		return ErrNoDisponible
+	}
+	pilotos, err := api.AllDrivers(client.ListOptions{})
+	if err != nil {
+		return err
+	}
+	carreras, err := api.AllRaces(client.ListOptions{Sort: "date_start", Type: "all"})
+	if err != nil {
+		return err
+	}
+	a := &app{api: api, out: bufio.NewWriter(os.Stdout)}
+	a.pestanas = []*pantalla{a.pantallaPilotos(pilotos), a.pantallaCarreras(carreras)}
+
+	restaurar, err := modoCrudo(os.Stdin)
+	if err != nil {
+		return ErrNoDisponible
+	}
+	// Pantalla alternativa y cursor oculto; se deshace al salir.
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+		restaurar()
+	}()
+
+	buf := make([]byte, 64)
+	for {
+		a.dibujar()
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, e := range leerTeclas(buf[:n]) {
+			if e.t == teclaSalir {
+				return nil
+			}
+			a.manejar(e)
+		}
+	}
+}
+
+func (a *app) actualPantalla() *pantalla {
+	if len(a.pila) > 0 {
+		return a.pila[len(a.pila)-1]
+	}
+	return a.pestanas[a.actual]
+}
+
+func (a *app) manejar(e evento) {
+	p := a.actualPantalla()
+	lista := p.visibles()
+	_, alto := tamano(os.Stdout)
+	pagina := a.altoLista(p, alto)
+	a.mensaje = ""
+
+	switch e.t {
+	case teclaArriba:
+		p.sel--
+	case teclaAbajo:
+		p.sel++
+	case teclaPagArriba:
+		p.sel -= pagina
+	case teclaPagAbajo:
+		p.sel += pagina
+	case teclaInicio:
+		p.sel = 0
+	case teclaFin:
+		p.sel = len(lista) - 1
+	case teclaTab, teclaDerecha, teclaIzquierda:
+		if len(a.pila) == 0 {
+			a.actual = (a.actual + 1) % len(a.pestanas)
+		}
+	case teclaEnter:
+		if p.sel < len(lista) && lista[p.sel].abrir != nil {
+			nueva, err := lista[p.sel].abrir()
+			if err != nil {
+				a.mensaje = "Error: " + err.Error()
+			} else {
+				a.pila = append(a.pila, nueva)
+			}
+		}
+	case teclaEsc:
+		if p.filtro != "" {
+			p.filtro, p.sel, p.desde = "", 0, 0
+		} else if len(a.pila) > 0 {
+			a.pila = a.pila[:len(a.pila)-1]
+		}
+	case teclaBorrar:
+		if p.filtro != "" {
+			_, n := utf8.DecodeLastRuneInString(p.filtro)
+			p.filtro = p.filtro[:len(p.filtro)-n]
+			p.sel, p.desde = 0, 0
+		}
+	case teclaRuna:
+		p.filtro += string(e.r)
+		p.sel, p.desde = 0, 0
+	}
+
+	lista = p.visibles()
+	if p.sel >= len(lista) {
+		p.sel = len(lista) - 1
+	}
+	if p.sel < 0 {
+		p.sel = 0
+	}
+}
+
+// altoLista es la cantidad de filas de la lista que caben en pantalla.
+func (a *app) altoLista(p *pantalla, alto int) int {
+	// pestañas, titulo, cabecera, busqueda y ayuda
+	n := alto - 6 - len(p.info)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func (a *app) dibujar() {
+	ancho, alto := tamano(os.Stdout)
+	p := a.actualPantalla()
+	lista := p.visibles()
+	filas := a.altoLista(p, alto)
+	if p.sel < p.desde {
+		p.desde = p.sel
+	}
+	if p.sel >= p.desde+filas {
+		p.desde = p.sel - filas + 1
+	}
+
+	var lineas []string
+	if len(a.pila) == 0 {
+		var tabs []string
+		for i, t := range a.pestanas {
+			if i == a.actual {
+				tabs = append(tabs, "\x1b[7m "+t.titulo+" \x1b[0m")
+			} else {
+				tabs = append(tabs, " "+t.titulo+" ")
+			}
+		}
+		lineas = append(lineas, " F1 · "+strings.Join(tabs, " "))
+	} else {
+		var migas []string
+		for _, q := range a.pila {
+			migas = append(migas, q.titulo)
+		}
+		lineas = append(lineas, " F1 · "+a.pestanas[a.actual].titulo+" › "+strings.Join(migas, " › "))
+	}
+	lineas = append(lineas, "\x1b[1m "+p.titulo+"\x1b[0m")
+	for _, l := range p.info {
+		lineas = append(lineas, " "+l)
+	}
+	lineas = append(lineas, "\x1b[4m "+recortar(p.cabecera, ancho-2)+"\x1b[0m")
+	for i := p.desde; i < p.desde+filas; i++ {
+		if i >= len(lista) {
+			lineas = append(lineas, "")
+			continue
+		}
+		texto := " " + recortar(lista[i].texto, ancho-2)
+		if i == p.sel {
+			texto = "\x1b[7m" + texto + strings.Repeat(" ", max(0, ancho-render.Ancho(texto))) + "\x1b[0m"
+		}
+		lineas = append(lineas, texto)
+	}
+
+	estado := fmt.Sprintf(" Buscar: %s▏  (%d de %d)", p.filtro, len(lista), len(p.items))
+	if a.mensaje != "" {
+		estado = " " + a.mensaje
+	}
+	lineas = append(lineas, estado)
+	lineas = append(lineas, "\x1b[2m ↑↓ mover · Enter abrir · Esc volver · Tab pilotos/carreras · escribir para buscar · Ctrl+C salir\x1b[0m")
+
+	a.out.WriteString("\x1b[H\x1b[2J")
+	for i, l := range lineas {
+		if i >= alto {
+			break
+		}
+		a.out.WriteString(l)
+		if i < len(lineas)-1 && i < alto-1 {
+			a.out.WriteString("\r\n")
+		}
+	}
+	a.out.Flush()
+}
+
+func (a *app) pantallaPilotos(pilotos []client.Driver) *pantalla {
+	anchos := []int{4, 24, 18, 4}
+	p := &pantalla{titulo: "Pilotos", cabecera: columnas(anchos, "Nº", "Piloto", "Equipo", "País")}
+	for _, d := range pilotos {
+		d := d
+		nombre := d.FirstName + " " + d.LastName
+		p.items = append(p.items, item{
+			texto: columnas(anchos, strconv.Itoa(d.DriverNumber), nombre, d.TeamName, d.CountryCode),
+			abrir: func() (*pantalla, error) { return a.pantallaPiloto(d.DriverNumber, nombre) },
+		})
+	}
+	return p
+}
+
+func (a *app) pantallaCarreras(carreras []client.Race) *pantalla {
+	anchos := []int{6, 10, 16, 16, 6}
+	p := &pantalla{titulo: "Carreras", cabecera: columnas(anchos, "ID", "Fecha", "País", "Circuito", "Tipo")}
+	for _, c := range carreras {
+		c := c
+		tipo := ""
+		if c.SessionName == client.SessionSprint {
+			tipo = "Sprint"
+		}
+		p.items = append(p.items, item{
+			texto: columnas(anchos, strconv.Itoa(c.SessionKey), fecha(c.DateStart), c.CountryName, c.CircuitShortName, tipo),
+			abrir: func() (*pantalla, error) { return a.pantallaCarrera(c.SessionKey, c.CircuitShortName) },
+		})
+	}
+	return p
+}
+
+func (a *app) pantallaPiloto(numero int, nombre string) (*pantalla, error) {
+	d, err := a.api.DriverDetail(numero)
+	if err != nil {
+		return nil, err
+	}
+	r := d.PerformanceSummary
+	anchos := []int{22, 4, 13, 14}
+	p := &pantalla{
+		titulo: fmt.Sprintf("%s (#%d)", nombre, numero),
+		info: []string{
+			fmt.Sprintf("Victorias: %d · Top 3: %d · Puntos: %d · Vueltas lideradas: %d · Velocidad máxima: %.0f km/h", r.Wins, r.Top3Finishes, r.Points, r.LapsLed, r.MaxSpeed),
+			fmt.Sprintf("Sprints: %d victorias · %d puntos", r.SprintWins, r.SprintPoints),
+		},
+		cabecera: columnas(anchos, "Circuito", "Pos", "Mejor vuelta", "Vel. máx"),
+	}
+	for _, res := range append(d.RaceResults, d.SprintResults...) {
+		res := res
+		circuito := res.CircuitShortName
+		if res.Race == client.SessionSprint {
+			circuito += " (sprint)"
+		}
+		p.items = append(p.items, item{
+			texto: columnas(anchos, circuito, strconv.Itoa(res.Position), tiempo(res.BestLapDuration), fmt.Sprintf("%.0f km/h", res.MaxSpeed)),
+			abrir: func() (*pantalla, error) { return a.pantallaCarrera(res.SessionKey, circuito) },
+		})
+	}
+	return p, nil
+}
+
+func (a *app) pantallaCarrera(sessionKey int, circuito string) (*pantalla, error) {
+	d, err := a.api.RaceDetail(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	vr, vi := d.FastestLap, d.IdealLap
+	anchos := []int{4, 4, 24, 18, 9, 10, 3}
+	p := &pantalla{
+		titulo: fmt.Sprintf("%s (%d)", circuito, sessionKey),
+		info: []string{
+			fmt.Sprintf("Vuelta más rápida: %s %s (%.3f / %.3f / %.3f)", vr.Driver, tiempo(vr.LapDuration), vr.Sector1, vr.Sector2, vr.Sector3),
+			fmt.Sprintf("Vuelta ideal: %s (S1 %s · S2 %s · S3 %s)", tiempo(vi.LapDuration), vi.Sector1.Driver, vi.Sector2.Driver, vi.Sector3.Driver),
+			fmt.Sprintf("Velocidad máxima: %s %.0f km/h", d.MaxSpeed.Driver, d.MaxSpeed.SpeedKmh),
+		},
+		cabecera: columnas(anchos, "Pos", "Nº", "Piloto", "Equipo", "Estado", "Diferencia", "Pts"),
+	}
+	for _, r := range d.Classification {
+		r := r
+		diferencia := ""
+		if r.GapToWinner != nil {
+			diferencia = fmt.Sprintf("+%.3f", *r.GapToWinner)
+		}
+		p.items = append(p.items, item{
+			texto: columnas(anchos, strconv.Itoa(r.Position), strconv.Itoa(r.DriverNumber), r.Driver, r.TeamName,
+				r.Status, diferencia, strconv.Itoa(r.Points)),
+			abrir: func() (*pantalla, error) { return a.pantallaPiloto(r.DriverNumber, r.Driver) },
+		})
+	}
+	return p, nil
+}
+
+type tecla int
+
+const (
+	teclaRuna tecla = iota
+	teclaArriba
+	teclaAbajo
+	teclaIzquierda
+	teclaDerecha
+	teclaPagArriba
+	teclaPagAbajo
+	teclaInicio
+	teclaFin
+	teclaEnter
+	teclaEsc
+	teclaBorrar
+	teclaTab
+	teclaSalir
+)
+
+type evento struct {
+	t tecla
+	r rune
+}
+
+// leerTeclas traduce los bytes leidos de la terminal, incluyendo las
+// secuencias de escape de flechas, Inicio/Fin y RePag/AvPag.
+func leerTeclas(b []byte) []evento {
+	var eventos []evento
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c == 0x1b && i+1 < len(b) && (b[i+1] == '[' || b[i+1] == 'O'):
+			j := i + 2
+			for j < len(b) && (b[j] < 0x40 || b[j] > 0x7e) {
+				j++
+			}
+			if j >= len(b) {
+				return eventos
+			}
+			switch string(b[i+2 : j+1]) {
+			case "A":
+				eventos = append(eventos, evento{t: teclaArriba})
+			case "B":
+				eventos = append(eventos, evento{t: teclaAbajo})
+			case "C":
+				eventos = append(eventos, evento{t: teclaDerecha})
+			case "D":
+				eventos = append(eventos, evento{t: teclaIzquierda})
+			case "H", "1~", "7~":
+				eventos = append(eventos, evento{t: teclaInicio})
+			case "F", "4~", "8~":
+				eventos = append(eventos, evento{t: teclaFin})
+			case "5~":
+				eventos = append(eventos, evento{t: teclaPagArriba})
+			case "6~":
+				eventos = append(eventos, evento{t: teclaPagAbajo})
+			}
+			i = j + 1
+			continue
+		case c == 0x1b:
+			eventos = append(eventos, evento{t: teclaEsc})
+		case c == '\r' || c == '\n':
+			eventos = append(eventos, evento{t: teclaEnter})
+		case c == 0x7f || c == 0x08:
+			eventos = append(eventos, evento{t: teclaBorrar})
+		case c == '\t':
+			eventos = append(eventos, evento{t: teclaTab})
+		case c == 0x03 || c == 0x11: // Ctrl+C, Ctrl+Q
+			eventos = append(eventos, evento{t: teclaSalir})
+		case c >= 0x20:
+			r, n := utf8.DecodeRune(b[i:])
+			eventos = append(eventos, evento{t: teclaRuna, r: r})
+			i += n
+			continue
+		}
+		i++
+	}
+	return eventos
+}
+
+// columnas rellena cada celda hasta su ancho; la ultima no se rellena.
+func columnas(anchos []int, celdas ...string) string {
+	var b strings.Builder
+	for i, c := range celdas {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		b.WriteString(c)
+		if i < len(celdas)-1 && i < len(anchos) {
+			b.WriteString(strings.Repeat(" ", max(0, anchos[i]-render.Ancho(c))))
+		}
+	}
+	return b.String()
+}
+
+func recortar(s string, ancho int) string {
+	if ancho <= 0 {
+		return ""
+	}
+	if render.Ancho(s) <= ancho {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && render.Ancho(string(r)) > ancho-1 {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
+
+var sinAcentos = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+func normalizar(s string) string {
+	return sinAcentos.Replace(strings.ToLower(s))
+}
+
+func tiempo(segundos float64) string {
+	if segundos <= 0 {
+		return "-"
+	}
+	min := int(segundos) / 60
+	return fmt.Sprintf("%d:%06.3f", min, segundos-float64(min*60))
+}
+
+func fecha(s string) string {
+	if i := strings.Index(s, "T"); i != -1 {
+		return s[:i]
+	}
+	return s
+}